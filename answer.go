@@ -0,0 +1,200 @@
+package sdp
+
+import (
+    "errors"
+    "strconv"
+    )
+
+const (
+    noMedia string = "no media descriptions"
+    )
+
+// negotiatedAttributes are media attributes the answer derives from the
+// offer rather than copying them from the local section.
+var negotiatedAttributes = []string{"mid", "rtpmap", "fmtp", "sctpmap", "sctp-port", "max-message-size"}
+
+// Answer builds an RFC 3264 answer to offer. local describes what this side
+// supports: its origin, connection data and, for each media type and
+// protocol it accepts, a media section listing its port, formats and
+// attributes. Offered media with no acceptable local section or no common
+// format are rejected with port 0. Data channel sections match across the
+// legacy and RFC 8841 SCTP forms and are answered in the offered form. T.38
+// parameters are negotiated with AnswerT38. The offer and local are not
+// modified.
+func Answer(offer, local *SessionDescription) (*SessionDescription, error) {
+    if len(offer.MediaDescriptions) == 0 {
+        return nil, errors.New(noMedia)
+    }
+    answer := NewSessionDescription()
+    answer.Origin = local.Origin
+    answer.SessionName = local.SessionName
+    if answer.SessionName == "" {
+        answer.SessionName = "-"
+    }
+    answer.Connection = local.Connection
+    answer.Times = append([]TimeDescription(nil), offer.Times...)
+    for _, a := range local.Attributes {
+        if !contains(directions, a.Key) {
+            answer.Attributes = append(answer.Attributes, a)
+        }
+    }
+    for i := range offer.MediaDescriptions {
+        answer.MediaDescriptions = append(answer.MediaDescriptions, answerMedia(offer, &offer.MediaDescriptions[i], local))
+    }
+    return answer, nil
+}
+
+func answerMedia(offer *SessionDescription, om *MediaDescription, local *SessionDescription) MediaDescription {
+    var mid []Attribute
+    if v, ok := om.Attribute("mid"); ok {
+        mid = []Attribute{Attribute{"mid", v}}
+    }
+    if om.Port != 0 {
+        for j := range local.MediaDescriptions {
+            lm := &local.MediaDescriptions[j]
+            if lm.Type != om.Type || lm.Port == 0 {
+                continue
+            }
+            formats, attrs := negotiateMedia(om, lm)
+            if len(formats) == 0 {
+                continue
+            }
+            am := MediaDescription{
+                Type: lm.Type,
+                Port: lm.Port,
+                NumPorts: lm.NumPorts,
+                Proto: om.Proto,
+                Formats: formats,
+                Info: lm.Info,
+                Connections: append([]Connection(nil), lm.Connections...),
+                Bandwidths: append([]Bandwidth(nil), lm.Bandwidths...),
+                Key: lm.Key,
+            }
+            am.Attributes = mid
+            // Data channels have no direction (RFC 8841 section 6).
+            if !isSCTP(om.Proto) {
+                am.Attributes = append(am.Attributes, Attribute{answerDirection(offer.Direction(om), local.Direction(lm)), ""})
+            }
+            am.Attributes = append(am.Attributes, attrs...)
+            am.Attributes = append(am.Attributes, localAttributes(lm)...)
+            return am
+        }
+    }
+    return rejectMedia(om)
+}
+
+// rejectMedia returns the section rejecting m: port 0 and no attributes but
+// its mid.
+func rejectMedia(m *MediaDescription) MediaDescription {
+    r := MediaDescription{
+        Type: m.Type,
+        Port: 0,
+        Proto: m.Proto,
+        Formats: append([]string(nil), m.Formats...),
+    }
+    if v, ok := m.Attribute("mid"); ok {
+        r.Attributes = []Attribute{Attribute{"mid", v}}
+    }
+    return r
+}
+
+// negotiateMedia returns the formats of om that lm supports and the
+// attributes describing them, or no formats when the sections have nothing
+// in common.
+func negotiateMedia(om, lm *MediaDescription) ([]string, []Attribute) {
+    switch {
+    case isSCTP(om.Proto) && isSCTP(lm.Proto):
+        return negotiateSCTP(om, lm)
+    case lm.Proto != om.Proto:
+        return nil, nil
+    case om.isT38():
+        return negotiateT38(om, lm)
+    }
+    return negotiateFormats(om, lm)
+}
+
+// localAttributes returns the attributes of lm that an answer copies as is.
+func localAttributes(lm *MediaDescription) []Attribute {
+    return filterAttributes(lm.Attributes, func(a Attribute) bool {
+        return !contains(negotiatedAttributes, a.Key) && !contains(directions, a.Key) && !isT38Attribute(a.Key)
+    })
+}
+
+// negotiateFormats returns the offered formats of om that lm supports, in
+// the offer's order and with the offer's payload types, together with the
+// rtpmap and fmtp attributes describing them. Known codecs match with
+// CodecsCompatible, RTX-like payloads when their apt= targets match too,
+// and other formats by their token.
+func negotiateFormats(om, lm *MediaDescription) ([]string, []Attribute) {
+    matched := map[string]string{}
+    codec := func(m *MediaDescription, f string) (RTPCodecParameters, bool) {
+        pt, err := strconv.Atoi(f)
+        if err != nil {
+            pt = -1
+        }
+        return m.codecParameters(f, pt)
+    }
+    // Dependent payloads are matched in a second pass, once their targets are.
+    for _, dependent := range []bool{false, true} {
+        for _, f := range om.Formats {
+            oc, ohas := codec(om, f)
+            if (oc.Parameters["apt"] != "") != dependent {
+                continue
+            }
+            for _, g := range lm.Formats {
+                lc, lhas := codec(lm, g)
+                if ohas && lhas {
+                    if !CodecsCompatible(oc, lc) || (dependent && matched[oc.Parameters["apt"]] != lc.Parameters["apt"]) {
+                        continue
+                    }
+                } else if f != g {
+                    continue
+                }
+                matched[f] = g
+                break
+            }
+        }
+    }
+    var formats []string
+    var attrs []Attribute
+    for _, f := range om.Formats {
+        g, ok := matched[f]
+        if !ok {
+            continue
+        }
+        formats = append(formats, f)
+        or, ohas := om.RTPMap(f)
+        lr, lhas := lm.RTPMap(g)
+        if ohas {
+            attrs = append(attrs, Attribute{"rtpmap", or.String()})
+        } else if lhas {
+            lr.PayloadType, _ = strconv.Atoi(f)
+            attrs = append(attrs, Attribute{"rtpmap", lr.String()})
+        }
+        if fmtp, ok := lm.Fmtp(g); ok {
+            oc, _ := codec(om, f)
+            lc, _ := codec(lm, g)
+            offerFmtp, _ := om.Fmtp(f)
+            if fmtp = answerFmtp(oc, lc, offerFmtp, fmtp); fmtp != "" {
+                attrs = append(attrs, Attribute{"fmtp", f + " " + fmtp})
+            }
+        }
+    }
+    return formats, attrs
+}
+
+// answerDirection returns the direction an answerer with local capability
+// l uses in reply to offered direction o.
+func answerDirection(o, l string) string {
+    send := (o == "sendrecv" || o == "recvonly") && (l == "sendrecv" || l == "sendonly")
+    recv := (o == "sendrecv" || o == "sendonly") && (l == "sendrecv" || l == "recvonly")
+    switch {
+    case send && recv:
+        return "sendrecv"
+    case send:
+        return "sendonly"
+    case recv:
+        return "recvonly"
+    }
+    return "inactive"
+}
@@ -0,0 +1,69 @@
+package sdp
+
+import (
+    "testing"
+    )
+
+var capabilities = `v=0
+o=bob 2808844564 2808844564 IN IP4 198.51.100.9
+s=-
+c=IN IP4 198.51.100.9
+t=0 0
+m=audio 49172 RTP/AVP 99
+a=rtpmap:99 ILBC/8000
+a=fmtp:99 mode=30
+a=recvonly
+`
+
+func TestAnswer(t *testing.T) {
+    o, err := Decode(offer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    o.MediaDescriptions[0].Formats = []string{"97", "0"}
+    o.MediaDescriptions[0].Attributes = append(o.MediaDescriptions[0].Attributes, Attribute{"rtpmap", "97 iLBC/8000"})
+    l, err := Decode(capabilities)
+    if err != nil {
+        t.Fatal(err)
+    }
+    a, err := Answer(o, l)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if a.Origin.Username != "bob" || len(a.MediaDescriptions) != 2 {
+        t.Fatalf("wrong answer: %+v", a)
+    }
+    am := a.MediaDescriptions[0]
+    if am.Port != 49172 || len(am.Formats) != 1 || am.Formats[0] != "97" || am.Mid() != "a" || a.Direction(&am) != "recvonly" {
+        t.Errorf("wrong audio answer: %+v", am)
+    }
+    if fmtp, _ := am.Fmtp("97"); fmtp != "mode=30" {
+        t.Errorf("wrong fmtp: %q", fmtp)
+    }
+    if vm := a.MediaDescriptions[1]; vm.Port != 0 || vm.Mid() != "v" {
+        t.Errorf("video should be rejected: %+v", vm)
+    }
+    if errs := a.Validate(); errs != nil {
+        t.Errorf("invalid answer: %v", errs)
+    }
+    if _, err := Answer(l, &SessionDescription{}); err != nil {
+        t.Error(err)
+    }
+    if _, err := Answer(&SessionDescription{}, l); err == nil {
+        t.Errorf("answering an offer without media should fail")
+    }
+}
+
+func TestAnswerDirection(t *testing.T) {
+    for _, c := range [][3]string{
+        {"sendrecv", "sendrecv", "sendrecv"},
+        {"sendonly", "sendrecv", "recvonly"},
+        {"recvonly", "sendrecv", "sendonly"},
+        {"sendonly", "sendonly", "inactive"},
+        {"inactive", "sendrecv", "inactive"},
+    } {
+        if d := answerDirection(c[0], c[1]); d != c[2] {
+            t.Errorf("answerDirection(%s, %s) = %s, want %s", c[0], c[1], d, c[2])
+        }
+    }
+}
@@ -0,0 +1,186 @@
+package sdp
+
+import (
+    "reflect"
+    "sort"
+    "strings"
+    "time"
+    )
+
+// EqualOptions selects the differences Equal ignores.
+type EqualOptions struct {
+    // IgnoreOriginVersion ignores the session version in o=.
+    IgnoreOriginVersion bool
+    // IgnoreCandidates ignores a=candidate and a=end-of-candidates.
+    IgnoreCandidates bool
+    // IgnoreUnknownAttributes ignores attributes outside the built-in list
+    // AttrTypes starts with, such as x- extensions. Attributes carrying
+    // transport or media semantics, among them mid, fingerprint, setup and
+    // ssrc, are compared. Changes to AttrTypes do not affect Equal.
+    IgnoreUnknownAttributes bool
+}
+
+// Equal reports whether a and b are semantically equal: whether their
+// canonical forms, less what opts ignores, are identical.
+func Equal(a, b *SessionDescription, opts EqualOptions) bool {
+    ca, cb := a.Canonicalize(), b.Canonicalize()
+    for _, c := range []*SessionDescription{ca, cb} {
+        if opts.IgnoreOriginVersion {
+            c.Origin.SessionVersion = ""
+        }
+        keep := func(a Attribute) bool {
+            if opts.IgnoreCandidates && (a.Key == "candidate" || a.Key == "end-of-candidates") {
+                return false
+            }
+            return !opts.IgnoreUnknownAttributes || contains(builtinAttributes, a.Key)
+        }
+        c.Attributes = filterAttributes(c.Attributes, keep)
+        for i := range c.MediaDescriptions {
+            c.MediaDescriptions[i].Attributes = filterAttributes(c.MediaDescriptions[i].Attributes, keep)
+        }
+    }
+    return reflect.DeepEqual(ca, cb)
+}
+
+func filterAttributes(attrs []Attribute, keep func(Attribute) bool) []Attribute {
+    var kept []Attribute
+    for _, a := range attrs {
+        if keep(a) {
+            kept = append(kept, a)
+        }
+    }
+    return kept
+}
+
+// Canonicalize returns a normalized copy of sd. Network and address types
+// are upper-cased, surrounding and repeated whitespace is removed from
+// field values, media types and rtpmap encoding names are lower-cased,
+// protocols take their registered spelling, fmtp parameters are sorted,
+// attributes are sorted by key and value within each section, times are in
+// UTC and empty slices are nil. sd is not modified.
+func (sd *SessionDescription) Canonicalize() *SessionDescription {
+    c := *sd
+    c.Origin = Origin{
+        strings.TrimSpace(sd.Origin.Username),
+        strings.TrimSpace(sd.Origin.SessionId),
+        strings.TrimSpace(sd.Origin.SessionVersion),
+        strings.ToUpper(strings.TrimSpace(sd.Origin.NetType)),
+        strings.ToUpper(strings.TrimSpace(sd.Origin.AddrType)),
+        strings.TrimSpace(sd.Origin.UnicastAddr),
+    }
+    c.SessionName = strings.TrimSpace(sd.SessionName)
+    c.Info = strings.TrimSpace(sd.Info)
+    c.Uri = strings.TrimSpace(sd.Uri)
+    c.Emails = nil
+    for _, e := range sd.Emails {
+        c.Emails = append(c.Emails, Email{strings.TrimSpace(e.Address), strings.TrimSpace(e.Name)})
+    }
+    c.Phones = nil
+    for _, p := range sd.Phones {
+        c.Phones = append(c.Phones, Phone{strings.TrimSpace(p.Address), strings.TrimSpace(p.Name)})
+    }
+    if sd.Connection != (Connection{}) {
+        c.Connection = canonicalConnection(sd.Connection)
+    }
+    c.Bandwidths = canonicalBandwidths(sd.Bandwidths)
+    c.Times = nil
+    for _, t := range sd.Times {
+        ct := TimeDescription{Start: canonicalTime(t.Start), Stop: canonicalTime(t.Stop)}
+        for _, r := range t.Repeats {
+            cr := Repeat{Interval: r.Interval, Active: r.Active}
+            if len(r.Offsets) > 0 {
+                cr.Offsets = append(cr.Offsets, r.Offsets...)
+            }
+            ct.Repeats = append(ct.Repeats, cr)
+        }
+        for _, z := range t.Zones {
+            ct.Zones = append(ct.Zones, Zone{canonicalTime(z.Time), z.Offset})
+        }
+        c.Times = append(c.Times, ct)
+    }
+    c.Key = Key{strings.TrimSpace(sd.Key.Method), strings.TrimSpace(sd.Key.Key)}
+    c.Attributes = canonicalAttributes(sd.Attributes)
+    c.MediaDescriptions = nil
+    for _, m := range sd.MediaDescriptions {
+        cm := m
+        cm.Type = strings.ToLower(strings.TrimSpace(m.Type))
+        cm.Proto = strings.TrimSpace(m.Proto)
+        if p, ok := LookupProtocol(cm.Proto); ok {
+            cm.Proto = p.Name
+        }
+        cm.Formats = append([]string(nil), m.Formats...)
+        cm.Info = strings.TrimSpace(m.Info)
+        cm.Connections = nil
+        for _, conn := range m.Connections {
+            cm.Connections = append(cm.Connections, canonicalConnection(conn))
+        }
+        cm.Bandwidths = canonicalBandwidths(m.Bandwidths)
+        cm.Key = Key{strings.TrimSpace(m.Key.Method), strings.TrimSpace(m.Key.Key)}
+        cm.Attributes = canonicalAttributes(m.Attributes)
+        c.MediaDescriptions = append(c.MediaDescriptions, cm)
+    }
+    return &c
+}
+
+func canonicalConnection(c Connection) Connection {
+    return Connection{
+        strings.ToUpper(strings.TrimSpace(c.NetType)),
+        strings.ToUpper(strings.TrimSpace(c.AddrType)),
+        strings.TrimSpace(c.Address),
+    }
+}
+
+func canonicalBandwidths(bws []Bandwidth) []Bandwidth {
+    var c []Bandwidth
+    for _, b := range bws {
+        c = append(c, Bandwidth{strings.ToUpper(strings.TrimSpace(b.Type)), strings.TrimSpace(b.Bandwidth)})
+    }
+    return c
+}
+
+func canonicalAttributes(attrs []Attribute) []Attribute {
+    var c []Attribute
+    for _, a := range attrs {
+        ca := Attribute{strings.TrimSpace(a.Key), strings.Join(strings.Fields(a.Value), " ")}
+        switch ca.Key {
+        case "fmtp":
+            ca.Value = canonicalFmtp(ca.Value)
+        case "rtpmap":
+            if r, err := parseRTPMap(ca.Value); err == nil {
+                r.EncodingName = strings.ToLower(r.EncodingName)
+                ca.Value = r.String()
+            }
+        }
+        c = append(c, ca)
+    }
+    sort.SliceStable(c, func(i, j int) bool {
+        if c[i].Key != c[j].Key {
+            return c[i].Key < c[j].Key
+        }
+        return c[i].Value < c[j].Value
+    })
+    return c
+}
+
+// canonicalFmtp sorts the ';' separated parameters of an fmtp value.
+func canonicalFmtp(v string) string {
+    tokens := strings.SplitN(v, " ", 2)
+    if len(tokens) != 2 {
+        return v
+    }
+    var params []string
+    for _, p := range strings.Split(tokens[1], ";") {
+        if p = strings.TrimSpace(p); p != "" {
+            params = append(params, p)
+        }
+    }
+    sort.Strings(params)
+    return tokens[0] + " " + strings.Join(params, ";")
+}
+
+func canonicalTime(t time.Time) time.Time {
+    if t.IsZero() {
+        return time.Time{}
+    }
+    return t.UTC()
+}
@@ -0,0 +1,71 @@
+package sdp
+
+import (
+    "strings"
+    "testing"
+    )
+
+func TestEqual(t *testing.T) {
+    a, err := Decode(offer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    reordered := strings.NewReplacer(
+        "c=IN IP4 203.0.113.1", "c=in ip4 203.0.113.1",
+        "a=mid:v\na=rtpmap:99 h263-1998/90000", "a=rtpmap:99  h263-1998/90000\na=mid:v",
+    ).Replace(offer)
+    b, err := Decode(reordered + "a=fmtp:99 b=2;a=1\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+    a.MediaDescriptions[1].Attributes = append(a.MediaDescriptions[1].Attributes, Attribute{"fmtp", "99 a=1; b=2"})
+    if !Equal(a, b, EqualOptions{}) {
+        t.Errorf("should be equal:\n%+v\n%+v", a.Canonicalize(), b.Canonicalize())
+    }
+    if a.Connection.NetType != "IN" || b.Connection.NetType != "in" {
+        t.Errorf("Canonicalize modified its receiver")
+    }
+    b.Origin.SessionVersion = "2"
+    b.MediaDescriptions[0].Attributes = append(b.MediaDescriptions[0].Attributes,
+        Attribute{"candidate", "1 1 UDP 2130706431 203.0.113.1 54400 typ host"}, Attribute{"x-foo", "bar"})
+    if Equal(a, b, EqualOptions{}) || Equal(a, b, EqualOptions{IgnoreOriginVersion: true, IgnoreCandidates: true}) {
+        t.Errorf("should not be equal")
+    }
+    if !Equal(a, b, EqualOptions{true, true, true}) {
+        t.Errorf("should be equal when ignoring version, candidates and unknown attributes")
+    }
+    for _, a := range []Attribute{{"fingerprint", "sha-256 00:11:22:33"}, {"mid", "x"}, {"setup", "active"}, {"ice-options", "trickle"}, {"ssrc", "1 cname:c"}} {
+        c := b.Clone()
+        c.MediaDescriptions[0].Attributes = append(c.MediaDescriptions[0].Attributes, a)
+        if Equal(b, c, EqualOptions{true, true, true}) {
+            t.Errorf("%s change not detected", a.Key)
+        }
+    }
+    c := b.Clone()
+    c.Attributes[2].Value = "sha-256 00:11:22:33:44:55:66:77"
+    if Equal(b, c, EqualOptions{true, true, true}) {
+        t.Errorf("fingerprint change not detected")
+    }
+    AttrTypes = append(AttrTypes, "x-foo")
+    defer func() { AttrTypes = AttrTypes[:len(AttrTypes)-1] }()
+    if !Equal(a, b, EqualOptions{true, true, true}) {
+        t.Errorf("AttrTypes changed the result of Equal")
+    }
+}
+
+func TestEqualCase(t *testing.T) {
+    a, err := Decode(offer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, err := Decode(strings.NewReplacer("m=audio 54400 RTP/AVP", "m=AUDIO 54400 rtp/avp", "h263-1998", "H263-1998").Replace(offer))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !Equal(a, b, EqualOptions{}) {
+        t.Errorf("should be equal:\n%+v\n%+v", a.Canonicalize(), b.Canonicalize())
+    }
+    if c := b.Canonicalize(); c.MediaDescriptions[0].Type != "audio" || c.MediaDescriptions[0].Proto != "RTP/AVP" {
+        t.Errorf("wrong canonical m= line: %v", c.MediaDescriptions[0])
+    }
+}
@@ -0,0 +1,47 @@
+package sdp
+
+import (
+    "slices"
+    )
+
+// Clone returns a deep copy of sd. Assigning a SessionDescription copies
+// only the struct, leaving its slices shared with the original; a clone
+// can be modified freely, for instance to turn an offer into an answer.
+func (sd *SessionDescription) Clone() *SessionDescription {
+    c := *sd
+    c.Emails = slices.Clone(sd.Emails)
+    c.Phones = slices.Clone(sd.Phones)
+    c.Bandwidths = slices.Clone(sd.Bandwidths)
+    c.Times = nil
+    for _, t := range sd.Times {
+        c.Times = append(c.Times, t.Clone())
+    }
+    c.Attributes = slices.Clone(sd.Attributes)
+    c.MediaDescriptions = nil
+    for i := range sd.MediaDescriptions {
+        c.MediaDescriptions = append(c.MediaDescriptions, *sd.MediaDescriptions[i].Clone())
+    }
+    return &c
+}
+
+// Clone returns a deep copy of m.
+func (m *MediaDescription) Clone() *MediaDescription {
+    c := *m
+    c.Formats = slices.Clone(m.Formats)
+    c.Connections = slices.Clone(m.Connections)
+    c.Bandwidths = slices.Clone(m.Bandwidths)
+    c.Attributes = slices.Clone(m.Attributes)
+    return &c
+}
+
+// Clone returns a deep copy of t.
+func (t TimeDescription) Clone() TimeDescription {
+    c := t
+    c.Repeats = nil
+    for _, r := range t.Repeats {
+        r.Offsets = slices.Clone(r.Offsets)
+        c.Repeats = append(c.Repeats, r)
+    }
+    c.Zones = slices.Clone(t.Zones)
+    return c
+}
@@ -0,0 +1,41 @@
+package sdp
+
+import (
+    "reflect"
+    "testing"
+    )
+
+func TestClone(t *testing.T) {
+    orig, err := Decode(s1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    c := orig.Clone()
+    if !reflect.DeepEqual(orig, c) {
+        t.Fatalf("clone differs:\n%+v\n%+v", orig, c)
+    }
+    c.Emails[0].Name = "x"
+    c.Times[0].Repeats[0].Offsets[0] = 1
+    c.Times[0].Zones[0].Offset = 1
+    c.Attributes[0].Key = "sendonly"
+    c.MediaDescriptions[1].Attributes[0].Value = "x"
+    c.MediaDescriptions[1].Port = 1
+    c.MediaDescriptions[0].Formats[0] = "8"
+    if d, err := Decode(s1); err != nil || !reflect.DeepEqual(orig, d) {
+        t.Errorf("modifying the clone changed the original: %+v", orig)
+    }
+}
+
+func TestAttributeHelpers(t *testing.T) {
+    m := MediaDescription{Attributes: []Attribute{Attribute{"sendrecv", ""}, Attribute{"rtpmap", "0 PCMU/8000"}, Attribute{"rtpmap", "8 PCMA/8000"}}}
+    shallow := m
+    m.SetAttribute("ptime", "20")
+    m.SetAttribute("rtpmap", "0 pcmu/8000")
+    m.DeleteAttribute("sendrecv")
+    if v, _ := m.Attribute("rtpmap"); v != "0 pcmu/8000" || len(m.Attributes) != 3 {
+        t.Errorf("wrong attributes: %v", m.Attributes)
+    }
+    if v, _ := shallow.Attribute("rtpmap"); v != "0 PCMU/8000" || len(shallow.Attributes) != 3 {
+        t.Errorf("shallow copy modified: %v", shallow.Attributes)
+    }
+}
@@ -0,0 +1,197 @@
+// Command sdptool inspects and rewrites SDP files.
+//
+// Usage:
+//
+//   sdptool lint FILE...          report parse and validation errors
+//   sdptool fmt [-lf] FILE        canonicalize FILE and re-encode it with CRLF (or LF) line endings
+//   sdptool json FILE             convert SDP to JSON, or JSON back to SDP
+//   sdptool diff OLD NEW          print the semantic changes from OLD to NEW
+//   sdptool answer OFFER CAPS     answer OFFER using the SDP in CAPS as local capabilities
+//
+// A FILE of "-" reads standard input.
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+
+    "github.com/WeMeetAgain/go-sdp"
+    )
+
+const usage = `usage:
+  sdptool lint FILE...
+  sdptool fmt [-lf] FILE
+  sdptool json FILE
+  sdptool diff OLD NEW
+  sdptool answer OFFER CAPS
+`
+
+// A command runs with the arguments following its name. It returns the exit
+// status when it completes, which is not 0 when lint found problems.
+type command func(t *tool, args []string) (int, error)
+
+var commands = map[string]command{
+    "lint": (*tool).lint,
+    "fmt": (*tool).format,
+    "json": (*tool).convert,
+    "diff": (*tool).diff,
+    "answer": (*tool).answer,
+}
+
+var errUsage = errors.New("bad arguments")
+
+// tool holds the standard streams commands use.
+type tool struct {
+    stdin  io.Reader
+    stdout io.Writer
+    stderr io.Writer
+}
+
+func main() {
+    os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command named by args[0] and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+    if len(args) < 1 || commands[args[0]] == nil {
+        fmt.Fprint(stderr, usage)
+        return 2
+    }
+    status, err := commands[args[0]](&tool{stdin, stdout, stderr}, args[1:])
+    switch {
+    case err == errUsage:
+        fmt.Fprint(stderr, usage)
+        return 2
+    case err != nil:
+        fmt.Fprintln(stderr, "sdptool:", err)
+        return 1
+    }
+    return status
+}
+
+func (t *tool) read(name string) ([]byte, error) {
+    if name == "-" {
+        return io.ReadAll(t.stdin)
+    }
+    return os.ReadFile(name)
+}
+
+func (t *tool) decode(name string) (*sdp.SessionDescription, error) {
+    b, err := t.read(name)
+    if err != nil {
+        return nil, err
+    }
+    sd, err := sdp.Decode(string(b))
+    if err != nil {
+        return nil, fmt.Errorf("%s: %v", name, err)
+    }
+    return sd, nil
+}
+
+func (t *tool) lint(args []string) (int, error) {
+    if len(args) == 0 {
+        return 0, errUsage
+    }
+    status := 0
+    for _, name := range args {
+        b, err := t.read(name)
+        if err != nil {
+            return 0, err
+        }
+        sd, err := sdp.Decode(string(b))
+        if perr, ok := err.(*sdp.ParseError); ok {
+            fmt.Fprintf(t.stdout, "%s:%d: %v: %q\n", name, perr.Line, perr.Err, perr.Text)
+            status = 1
+            continue
+        } else if err != nil {
+            return 0, err
+        }
+        for _, verr := range sd.Validate() {
+            fmt.Fprintf(t.stdout, "%s: %v\n", name, verr)
+            status = 1
+        }
+    }
+    return status, nil
+}
+
+func (t *tool) format(args []string) (int, error) {
+    fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+    fs.SetOutput(t.stderr)
+    lf := fs.Bool("lf", false, "use LF line endings")
+    if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+        return 0, errUsage
+    }
+    sd, err := t.decode(fs.Arg(0))
+    if err != nil {
+        return 0, err
+    }
+    opts := sdp.EncodeOptions{}
+    if *lf {
+        opts.LineEnding = sdp.LF
+    }
+    return 0, sdp.NewEncoder(t.stdout, opts).Encode(sd.Canonicalize())
+}
+
+func (t *tool) convert(args []string) (int, error) {
+    if len(args) != 1 {
+        return 0, errUsage
+    }
+    b, err := t.read(args[0])
+    if err != nil {
+        return 0, err
+    }
+    if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+        sd := new(sdp.SessionDescription)
+        if err := json.Unmarshal(trimmed, sd); err != nil {
+            return 0, err
+        }
+        return 0, sdp.NewEncoder(t.stdout, sdp.EncodeOptions{}).Encode(sd)
+    }
+    sd, err := sdp.Decode(string(b))
+    if err != nil {
+        return 0, fmt.Errorf("%s: %v", args[0], err)
+    }
+    enc := json.NewEncoder(t.stdout)
+    enc.SetIndent("", "  ")
+    return 0, enc.Encode(sd)
+}
+
+func (t *tool) diff(args []string) (int, error) {
+    if len(args) != 2 {
+        return 0, errUsage
+    }
+    a, err := t.decode(args[0])
+    if err != nil {
+        return 0, err
+    }
+    b, err := t.decode(args[1])
+    if err != nil {
+        return 0, err
+    }
+    fmt.Fprint(t.stdout, sdp.FormatChanges(sdp.Diff(a, b)))
+    return 0, nil
+}
+
+func (t *tool) answer(args []string) (int, error) {
+    if len(args) != 2 {
+        return 0, errUsage
+    }
+    offer, err := t.decode(args[0])
+    if err != nil {
+        return 0, err
+    }
+    local, err := t.decode(args[1])
+    if err != nil {
+        return 0, err
+    }
+    ans, err := sdp.Answer(offer, local)
+    if err != nil {
+        return 0, err
+    }
+    return 0, sdp.NewEncoder(t.stdout, sdp.EncodeOptions{}).Encode(ans)
+}
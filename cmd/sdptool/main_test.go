@@ -0,0 +1,45 @@
+package main
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+    )
+
+func TestRun(t *testing.T) {
+    for _, tt := range []struct {
+        args   []string
+        stdin  string
+        status int
+        out    []string // substrings of the standard output
+    }{
+        {[]string{"lint", "../../testdata/rfc4566-example.sdp", "../../testdata/webrtc-chrome-offer.sdp"}, "", 0, nil},
+        {[]string{"lint", "../../testdata/sip-rfc4317-offer.sdp"}, "", 1, []string{"../../testdata/sip-rfc4317-offer.sdp"}},
+        {[]string{"lint", "-"}, "v=0\no=jdoe 1 IN IP4 10.47.16.5\n", 1, []string{`-:2: `, `"o=jdoe 1 IN IP4 10.47.16.5"`}},
+        {[]string{"fmt", "../../testdata/sip-single-codec.sdp"}, "", 0, []string{"v=0\r\n", "m=audio 49174 RTP/AVP 0\r\n"}},
+        {[]string{"fmt", "-lf", "../../testdata/sip-single-codec.sdp"}, "", 0, []string{"s=-\nc=IN IP4 host.biloxi.example.com\n"}},
+        {[]string{"json", "../../testdata/sip-single-codec.sdp"}, "", 0, []string{`"username": "bob"`, `"formats": [`}},
+        {[]string{"json", "-"}, `{"version":0,"origin":{"username":"-","sessionId":"1","sessionVersion":"1","netType":"IN","addrType":"IP4","unicastAddr":"0.0.0.0"},"sessionName":"-"}`, 0, []string{"o=- 1 1 IN IP4 0.0.0.0\r\n"}},
+        {[]string{"diff", "../../testdata/sip-rfc4317-offer.sdp", "../../testdata/sip-single-codec.sdp"}, "", 0, []string{"session: version changed:", "m[1]: media rejected"}},
+        {[]string{"diff", "../../testdata/sip-single-codec.sdp", "../../testdata/sip-single-codec.sdp"}, "", 0, nil},
+        {[]string{"answer", "../../testdata/sip-rfc4317-offer.sdp", "../../testdata/sip-single-codec.sdp"}, "", 0, []string{"m=audio 49174 RTP/AVP 0\r\n", "m=video 0 RTP/AVP 31 32\r\n"}},
+        {[]string{"diff", "../../testdata/sip-single-codec.sdp"}, "", 2, nil},
+        {[]string{"fmt", "../../testdata/missing.sdp"}, "", 1, nil},
+        {[]string{"convert"}, "", 2, nil},
+        {nil, "", 2, nil},
+    } {
+        var stdout, stderr bytes.Buffer
+        status := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+        if status != tt.status {
+            t.Errorf("%v: status %d, want %d\n%s", tt.args, status, tt.status, stderr.String())
+        }
+        for _, want := range tt.out {
+            if !strings.Contains(stdout.String(), want) {
+                t.Errorf("%v: missing %q in\n%s", tt.args, want, stdout.String())
+            }
+        }
+        if tt.out == nil && tt.status == 0 && stdout.Len() != 0 {
+            t.Errorf("%v: unexpected output\n%s", tt.args, stdout.String())
+        }
+    }
+}
@@ -0,0 +1,102 @@
+package sdp
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    )
+
+// RTPMap is the value of an a=rtpmap attribute:
+// <payload type> <encoding name>/<clock rate>[/<channels>].
+type RTPMap struct {
+    PayloadType  int
+    EncodingName string
+    ClockRate    int
+    Channels     int
+}
+
+func (r RTPMap) String() string {
+    s := strconv.Itoa(r.PayloadType) + " " + r.EncodingName + "/" + strconv.Itoa(r.ClockRate)
+    if r.Channels > 0 {
+        s += "/" + strconv.Itoa(r.Channels)
+    }
+    return s
+}
+
+func parseRTPMap(s string) (RTPMap, error) {
+    tokens := strings.Fields(s)
+    if len(tokens) != 2 {
+        return RTPMap{}, errors.New(badGrammar)
+    }
+    pt, err := strconv.Atoi(tokens[0])
+    if err != nil {
+        return RTPMap{}, err
+    }
+    enc := strings.Split(tokens[1], "/")
+    if len(enc) < 2 || len(enc) > 3 || enc[0] == "" {
+        return RTPMap{}, errors.New(badGrammar)
+    }
+    r := RTPMap{PayloadType: pt, EncodingName: enc[0]}
+    if r.ClockRate, err = strconv.Atoi(enc[1]); err != nil {
+        return RTPMap{}, err
+    }
+    if len(enc) == 3 {
+        if r.Channels, err = strconv.Atoi(enc[2]); err != nil {
+            return RTPMap{}, err
+        }
+    }
+    return r, nil
+}
+
+// sameCodec reports whether a and b describe the same encoding, ignoring the
+// payload type. Encoding names are case-insensitive and an omitted channel
+// count means one channel.
+func sameCodec(a, b RTPMap) bool {
+    ac, bc := a.Channels, b.Channels
+    if ac == 0 {
+        ac = 1
+    }
+    if bc == 0 {
+        bc = 1
+    }
+    return strings.EqualFold(a.EncodingName, b.EncodingName) && a.ClockRate == b.ClockRate && ac == bc
+}
+
+// PayloadTypes returns the formats of an RTP media description as payload
+// type numbers, in m= line order, which is the order of preference. It fails
+// for non-RTP protocols, whose formats are opaque tokens such as
+// webrtc-datachannel, and for formats that are not numbers in 0-127.
+func (m *MediaDescription) PayloadTypes() ([]int, error) {
+    if !isRTP(m.Proto) {
+        return nil, errors.New(notRTP)
+    }
+    var pts []int
+    for _, f := range m.Formats {
+        pt, err := strconv.ParseUint(f, 10, 7)
+        if err != nil {
+            return nil, err
+        }
+        pts = append(pts, int(pt))
+    }
+    return pts, nil
+}
+
+// RTPMap returns the a=rtpmap entry for payload type pt.
+func (m *MediaDescription) RTPMap(pt string) (RTPMap, bool) {
+    for _, v := range m.AttributeValues("rtpmap") {
+        if r, err := parseRTPMap(v); err == nil && strconv.Itoa(r.PayloadType) == pt {
+            return r, true
+        }
+    }
+    return RTPMap{}, false
+}
+
+// Fmtp returns the a=fmtp parameters for payload type pt.
+func (m *MediaDescription) Fmtp(pt string) (string, bool) {
+    for _, v := range m.AttributeValues("fmtp") {
+        if tokens := strings.SplitN(v, " ", 2); len(tokens) == 2 && tokens[0] == pt {
+            return tokens[1], true
+        }
+    }
+    return "", false
+}
@@ -0,0 +1,35 @@
+package sdp
+
+import (
+    "reflect"
+    "testing"
+    )
+
+func TestFormats(t *testing.T) {
+    var m MediaDescription
+    if err := m.UnmarshalText([]byte("audio 49170 RTP/AVP 0 8 97")); err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(m.Formats, []string{"0", "8", "97"}) {
+        t.Errorf("wrong formats: %v", m.Formats)
+    }
+    if pts, err := m.PayloadTypes(); err != nil || !reflect.DeepEqual(pts, []int{0, 8, 97}) {
+        t.Errorf("wrong payload types: %v %v", pts, err)
+    }
+    if m.String() != "audio 49170 RTP/AVP 0 8 97" {
+        t.Errorf("wrong m= line: %s", m.String())
+    }
+    m.Formats = append(m.Formats, "128")
+    if _, err := m.PayloadTypes(); err == nil {
+        t.Errorf("payload type 128 should fail")
+    }
+    if err := m.UnmarshalText([]byte("application 9 udp webrtc-datachannel")); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := m.PayloadTypes(); err == nil || m.Formats[0] != "webrtc-datachannel" {
+        t.Errorf("non-RTP formats: %v %v", m.Formats, err)
+    }
+    if err := m.UnmarshalText([]byte("audio 49170 RTP/AVP 0  8")); err == nil {
+        t.Errorf("empty format should fail")
+    }
+}
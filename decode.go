@@ -12,6 +12,7 @@ const (
     noLine string = "no line"
     badChar string = "bad character found"
     badGrammar string = "bad grammar found"
+    badDuration string = "bad duration"
     )
 
 const (
@@ -30,13 +31,16 @@ func Decode(str string) (*SessionDescription, error) {
     return sdp.Decode(str)
 }
 
-var DefaultRules = []func(*SDPParser,string) error{versionLine, originLine, sessionNameLine, infoLine, uriLine, emailLine, phoneLine, connectionLine, timeLine, repeatLine, zoneLine, keyLine, attrLine, mediaLine, mediaInfoLine, mediaConnectionLine, mediaBandwidthLine, mediaAttrLine}
+var DefaultRules = []func(*SDPParser,string) error{versionLine, originLine, sessionNameLine, infoLine, uriLine, emailLine, phoneLine, connectionLine, bandwidthLine, timeLine, repeatLine, zoneLine, keyLine, attrLine, mediaLine, mediaInfoLine, mediaConnectionLine, mediaBandwidthLine, mediaKeyLine, mediaAttrLine}
 
 func NewSDPParser() *SDPParser {
     return &SDPParser{NewSessionDescription(),DefaultRules,0}
 }
 
 func (p *SDPParser) Next(s string) error {
+    if p.Index < 0 || p.Index >= len(p.Rules) {
+        return errors.New(badChar)
+    }
     err := p.Rules[p.Index](p,s)
     if err != nil && err != errors.New(noLine) {
         return err
@@ -44,14 +48,43 @@ func (p *SDPParser) Next(s string) error {
     return nil
 }
 
+// ParseError is returned by Decode for a line that cannot be parsed.
+type ParseError struct {
+    Line int // 1-based line number
+    Text string
+    Err error
+}
+
+func (e *ParseError) Error() string {
+    return "sdp: line " + strconv.Itoa(e.Line) + ": " + e.Err.Error() + ": " + strconv.Quote(e.Text)
+}
+
+func (e *ParseError) Unwrap() error {
+    return e.Err
+}
+
+// Decode parses str line by line into p.SD. Empty lines are skipped; any
+// other line must have the form <type>=<value>. Every failure, including a
+// line too long to scan, is reported as a *ParseError.
 func (p *SDPParser) Decode(str string) (*SessionDescription, error) {
     scanner := bufio.NewScanner(strings.NewReader(str))
+    n := 0
     for scanner.Scan() {
         line := scanner.Text()
+        n++
+        if line == "" {
+            continue
+        }
+        if len(line) < 2 || line[1] != '=' {
+            return p.SD, &ParseError{n, line, errors.New(badGrammar)}
+        }
         if err := p.Next(line); err != nil {
-            return p.SD, err
+            return p.SD, &ParseError{n, line, err}
         }
     }
+    if err := scanner.Err(); err != nil {
+        return p.SD, &ParseError{n + 1, "", err}
+    }
     return p.SD, nil
 }
 
@@ -168,6 +201,22 @@ func connectionLine(p *SDPParser,line string) error {
     return nil
 }
 
+func bandwidthLine(p *SDPParser,line string) error {
+    if line[0] == 'b' {
+        if b, err := parseBandwidth(line[2:]); err == nil {
+            p.SD.Bandwidths = append(p.SD.Bandwidths, b)
+        } else {
+            return err
+        }
+    } else {
+        p.Index++
+        if err := p.Next(line); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
 func timeLine(p *SDPParser,line string) error {
     if line[0] == 't' {
         if t, err := parseTime(line[2:]); err == nil {
@@ -187,6 +236,9 @@ func timeLine(p *SDPParser,line string) error {
 
 func repeatLine(p *SDPParser,line string) error {
     if line[0] == 'r' {
+        if len(p.SD.Times) == 0 {
+            return errors.New(badGrammar)
+        }
         if r, err := parseRepeat(line[2:]); err == nil {
             p.SD.Times[(len(p.SD.Times)-1)].Repeats = append(p.SD.Times[(len(p.SD.Times)-1)].Repeats,r)
         } else {
@@ -203,6 +255,9 @@ func repeatLine(p *SDPParser,line string) error {
 
 func zoneLine(p *SDPParser,line string) error {
     if line[0] == 'z' {
+        if len(p.SD.Times) == 0 {
+            return errors.New(badGrammar)
+        }
         if z, err := parseZones(line[2:]); err == nil {
             p.SD.Times[(len(p.SD.Times)-1)].Zones = z
             p.Index = p.Index-2
@@ -258,6 +313,8 @@ func mediaLine(p *SDPParser,line string) error {
         } else {
             return err
         }
+    } else if len(p.SD.MediaDescriptions) == 0 {
+        return errors.New(badChar)
     } else {
         p.Index++
         if err := p.Next(line); err != nil {
@@ -302,7 +359,7 @@ func mediaConnectionLine(p *SDPParser,line string) error {
 func mediaBandwidthLine(p *SDPParser,line string) error {
     if line[0] == 'b' {
         if b, err := parseBandwidth(line[2:]); err == nil {
-            p.SD.MediaDescriptions[(len(p.SD.MediaDescriptions)-1)].Bandwidths = append(p.SD.MediaDescriptions[(len(p.SD.Times)-1)].Bandwidths, b)
+            p.SD.MediaDescriptions[(len(p.SD.MediaDescriptions)-1)].Bandwidths = append(p.SD.MediaDescriptions[(len(p.SD.MediaDescriptions)-1)].Bandwidths, b)
         } else {
             return err
         }
@@ -315,6 +372,23 @@ func mediaBandwidthLine(p *SDPParser,line string) error {
     return nil
 }
 
+func mediaKeyLine(p *SDPParser,line string) error {
+    if line[0] == 'k' {
+        if k, err := parseKey(line[2:]); err == nil {
+            p.SD.MediaDescriptions[(len(p.SD.MediaDescriptions)-1)].Key = k
+        } else {
+            return err
+        }
+        p.Index++
+    } else {
+        p.Index++
+        if err := p.Next(line); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
 func mediaAttrLine(p *SDPParser,line string) error {
     if line[0] == 'a' {
         if a, err := parseAttribute(line[2:]); err == nil {
@@ -322,8 +396,11 @@ func mediaAttrLine(p *SDPParser,line string) error {
         } else {
             return err
         }
+    } else if line[0] == 'm' {
+        p.Index = p.Index - 5
+        return p.Next(line)
     } else {
-        p.Index = p.Index - 4
+        return errors.New(badChar)
     }
     return nil
 }
@@ -353,73 +430,56 @@ func parseInformation(s string) (string, error) {
 }
 
 func parseEmail(s string) (Email, error) {
-    tokens := strings.Split(s," ")
-    if len(tokens) == 1 {
-        return Email{
-            Address: tokens[0],
-        }, nil
-    } else {
-        if bracket1, bracket2 := strings.Index(s,"<"), strings.Index(s,">"); bracket1 != -1 && bracket2 != -1 {
-            if (bracket1-2) > -1 {
-                return Email{
-                    Address: s[(bracket1+1):(bracket2)],
-                    Name: s[0:(bracket1-1)],
-                }, nil
-            } else {
-                return Email{}, errors.New(badGrammar)
-            }
-        } else if bracket1 != -1 || bracket2 != -1 {
-            return Email{}, errors.New(badGrammar)
-        }
-        if paren1, paren2 := strings.Index(s,"("), strings.Index(s,")"); paren1 != -1 && paren2 != -1 {
-            if (paren1-2) > -1 {
-                return Email{
-                    Address: s[0:(paren1-1)],
-                    Name: s[(paren1+1):(paren2)],
-                }, nil
-            } else {
-                return Email{}, errors.New(badGrammar)
-            }
-        } else if paren1 != -1 || paren2 != -1 {
-            return Email{}, errors.New(badGrammar)
-        }
+    address, name, err := parseContact(s)
+    if err != nil {
+        return Email{}, err
+    }
+    if name == "" && strings.Contains(address, " ") {
         return Email{}, errors.New(badGrammar)
     }
+    return Email{address, name}, nil
 }
 
 func parsePhone(s string) (Phone, error) {
-    if bracket1, bracket2 := strings.Index(s,"<"), strings.Index(s,">"); bracket1 != -1 && bracket2 != -1 {
-        if (bracket1-2) > -1 {
-            return Phone{
-                Address: s[(bracket1+1):(bracket2)],
-                Name: s[0:(bracket1-1)],
-            }, nil
-        } else {
-            return Phone{}, errors.New(badGrammar)
-        }
-    } else if bracket1 != -1 || bracket2 != -1 {
-        return Phone{}, errors.New(badGrammar)
+    address, name, err := parseContact(s)
+    if err != nil {
+        return Phone{}, err
     }
-    if paren1, paren2 := strings.Index(s,"("), strings.Index(s,")"); paren1 != -1 && paren2 != -1 {
-        if (paren1-2) > -1 {
-            return Phone{
-                Address: s[0:(paren1-1)],
-                Name: s[(paren1+1):(paren2)],
-            }, nil
-        } else {
-            return Phone{}, errors.New(badGrammar)
+    return Phone{address, name}, nil
+}
+
+// parseContact splits the value of an e= or p= line, which is either a bare
+// address, "address (name)" or "name <address>".
+func parseContact(s string) (string, string, error) {
+    paren, bracket := strings.Index(s,"("), strings.Index(s,"<")
+    switch {
+    case paren != -1 && (bracket == -1 || paren < bracket):
+        end := strings.LastIndex(s,")")
+        address := strings.TrimSpace(s[:paren])
+        if end < paren || address == "" || strings.TrimSpace(s[end+1:]) != "" || strings.ContainsAny(address, "()<>") {
+            return "", "", errors.New(badGrammar)
+        }
+        return address, s[paren+1:end], nil
+    case bracket != -1:
+        end := strings.Index(s,">")
+        name := strings.TrimSpace(s[:bracket])
+        if end < bracket || name == "" || strings.TrimSpace(s[end+1:]) != "" {
+            return "", "", errors.New(badGrammar)
+        }
+        address := s[bracket+1:end]
+        if address == "" || strings.ContainsAny(address, "()<> ") {
+            return "", "", errors.New(badGrammar)
         }
-    } else if paren1 != -1 || paren2 != -1 {
-        return Phone{}, errors.New(badGrammar)
+        return address, name, nil
+    case strings.ContainsAny(s, ")>"):
+        return "", "", errors.New(badGrammar)
     }
-    return Phone {
-        Address: s,
-    }, nil
+    return s, "", nil
 }
 
 func parseBandwidth(s string) (Bandwidth, error) {
-    tokens := strings.Split(s," ")
-    if len(tokens) != 2 {
+    tokens := strings.SplitN(s,":",2)
+    if len(tokens) != 2 || tokens[0] == "" || tokens[1] == "" {
         return Bandwidth{}, errors.New(badGrammar)
     }
     return Bandwidth{
@@ -445,46 +505,90 @@ func parseTime(s string) (TimeDescription, error) {
     if len(tokens) != 2 {
         return TimeDescription{}, errors.New(badGrammar)
     }
-    start, err := strconv.ParseInt(tokens[0], 10, 64)
+    start, err := parseNTP(tokens[0])
     if err != nil {
         return TimeDescription{}, err
     }
-    stop, err := strconv.ParseInt(tokens[1], 10, 64)
+    stop, err := parseNTP(tokens[1])
     if err != nil {
         return TimeDescription{}, err
     }
     return TimeDescription{
-        Start: time.Unix(start - ntpUnix, 0),
-        Stop: time.Unix(stop - ntpUnix, 0),
+        Start: fromNTP(start),
+        Stop: fromNTP(stop),
     }, nil
 }
 
+// parseNTP reads a decimal NTP timestamp, which cannot be negative.
+func parseNTP(s string) (int64, error) {
+    secs, err := strconv.ParseInt(s, 10, 64)
+    if err != nil {
+        return 0, err
+    }
+    if secs < 0 {
+        return 0, errors.New(badGrammar)
+    }
+    return secs, nil
+}
+
+// fromNTP converts seconds since the NTP epoch to a time. 0 maps to the zero
+// time so that unbounded sessions round trip.
+func fromNTP(secs int64) time.Time {
+    if secs == 0 {
+        return time.Time{}
+    }
+    return time.Unix(secs - ntpUnix, 0)
+}
+
+// parseDuration reads an SDP typed-time: an integer number of seconds, or
+// of days, hours or minutes when suffixed with d, h or m. Only a leading
+// minus sign is allowed; compound forms such as 1h30m are rejected.
 func parseDuration(s string) (time.Duration, error) {
-    var interval time.Duration
-    if s[len(s)-1] == 'd' {
-        days, err := strconv.ParseInt(s[0:len(s)-1], 10, 64)
-        if err != nil {
-            return time.Nanosecond, err
+    unit := time.Second
+    if len(s) > 0 {
+        switch s[len(s)-1] {
+        case 'd':
+            unit = 24 * time.Hour
+        case 'h':
+            unit = time.Hour
+        case 'm':
+            unit = time.Minute
         }
-        interval, err = time.ParseDuration(strconv.FormatInt(days * 24, 10)+"h")
-        if err != nil {
-            return time.Nanosecond, err
+        if unit != time.Second || s[len(s)-1] == 's' {
+            s = s[:len(s)-1]
         }
-    } else if s[len(s)-1] == 'h'  ||
-    s[len(s)-1] == 'm'  ||
-    s[len(s)-1] == 's'  {
-        return time.ParseDuration(s)
-    } else {
-        seconds, err := strconv.ParseInt(s, 10, 64)
-        if err != nil {
-            return time.Nanosecond, err
-        }
-        interval, err = time.ParseDuration(strconv.FormatInt(seconds, 10)+"s")
-        if err != nil {
-            return time.Nanosecond, err
+    }
+    digits := strings.TrimPrefix(s, "-")
+    if digits == "" {
+        return 0, errors.New(badDuration)
+    }
+    for _, c := range digits {
+        if c < '0' || c > '9' {
+            return 0, errors.New(badDuration)
         }
     }
-    return interval, nil
+    n, err := strconv.ParseInt(s, 10, 64)
+    if err != nil {
+        return 0, err
+    }
+    d := time.Duration(n) * unit
+    if d / unit != time.Duration(n) {
+        return 0, errors.New(badDuration)
+    }
+    return d, nil
+}
+
+// parseRepeatDuration is parseDuration for r= fields, which cannot be
+// negative.
+func parseRepeatDuration(s string) (time.Duration, error) {
+    d, err := parseDuration(s)
+    if err != nil {
+        return 0, err
+    }
+    if d < 0 {
+        return 0, errors.New(badDuration)
+    }
+    return d, nil
 }
 
 func parseRepeat(s string) (Repeat, error) {
@@ -492,17 +596,17 @@ func parseRepeat(s string) (Repeat, error) {
     if len(tokens) < 3 {
         return Repeat{}, errors.New(badGrammar)
     }
-    interval, err := parseDuration(tokens[0])
+    interval, err := parseRepeatDuration(tokens[0])
     if err != nil {
         return Repeat{}, err
     }
-    active, err := parseDuration(tokens[1])
+    active, err := parseRepeatDuration(tokens[1])
     if err != nil {
         return Repeat{}, err
     }
     var offsets []time.Duration
     for i := 2; i < len(tokens); i++ {
-        o, err := parseDuration(tokens[i])
+        o, err := parseRepeatDuration(tokens[i])
         if err != nil {
             return Repeat{}, err
         }
@@ -522,7 +626,7 @@ func parseZones(s string) ([]Zone, error) {
     }
     var zones []Zone
     for i:=0; i<len(tokens); i=i+2 {
-        t, err := strconv.ParseInt(tokens[i], 10, 64)
+        t, err := parseNTP(tokens[i])
         if err != nil {
             return nil, err
         }
@@ -531,7 +635,7 @@ func parseZones(s string) ([]Zone, error) {
             return nil, err
         }
         z := Zone{
-            Time: time.Unix(t - ntpUnix, 0),
+            Time: fromNTP(t),
             Offset: offset,
         }
         zones = append(zones, z)
@@ -540,67 +644,152 @@ func parseZones(s string) ([]Zone, error) {
 }
 
 func parseKey(s string) (Key, error) {
-    tokens := strings.Split(s,":")
+    tokens := strings.SplitN(s,":",2)
     if !contains(KeyTypes, tokens[0]) {
         return Key{}, errors.New(badGrammar)
     }
     if len(tokens) == 1 {
         return Key{tokens[0], ""}, nil
-    } else if len(tokens) == 2 {
-        return Key{tokens[0], tokens[1]}, nil
-    } else {
-        return Key{}, errors.New(badGrammar)
     }
+    return Key{tokens[0], tokens[1]}, nil
 }
 
 func parseAttribute(s string) (Attribute, error) {
-    tokens := strings.Split(s,":")
-    if !contains(AttrTypes, tokens[0]) {
+    tokens := strings.SplitN(s,":",2)
+    if tokens[0] == "" || strings.ContainsAny(tokens[0], " \t") {
         return Attribute{}, errors.New(badGrammar)
     }
     if len(tokens) == 1 {
         return Attribute{tokens[0], ""}, nil
-    } else if len(tokens) == 2 {
-        return Attribute{tokens[0], tokens[1]}, nil
-    } else {
-        return Attribute{}, errors.New(badGrammar)
     }
+    return Attribute{tokens[0], tokens[1]}, nil
+}
+
+// parsePort reads a decimal port number in the range 0-65535.
+func parsePort(s string) (int, error) {
+    p, err := strconv.ParseUint(s, 10, 16)
+    if err != nil {
+        return 0, err
+    }
+    return int(p), nil
 }
 
 func parseMedia(s string) (MediaDescription, error) {
     tokens := strings.Split(s," ")
     m := *NewMediaDescription()
-    if len(tokens) != 4 {
+    if len(tokens) < 4 {
         return MediaDescription{}, errors.New(badGrammar)
     }
-    if !contains(MediaTypes, tokens[0]) {
+    if tokens[0] == "" || tokens[2] == "" {
         return MediaDescription{}, errors.New(badGrammar)
     }
-    if p := strings.Split(tokens[1],"/"); len(p) > 2 || len(p) < 1 {
+    p := strings.Split(tokens[1],"/")
+    if len(p) > 2 {
         return MediaDescription{}, errors.New(badGrammar)
-    } else if len(p) == 2 {
-        np, err := strconv.ParseInt(p[1], 10, 32)
-        if err != nil {
-            return m, err
-        }
-        m.NumPorts = int(np)
-        p, err := strconv.ParseInt(p[0], 10, 32)
+    }
+    port, err := parsePort(p[0])
+    if err != nil {
+        return m, err
+    }
+    m.Port = port
+    if len(p) == 2 {
+        np, err := parsePort(p[1])
         if err != nil {
             return m, err
         }
-        m.NumPorts = int(p)
-    } else {
-        p, err := strconv.ParseInt(p[0], 10, 32)
-        if err != nil {
-            return m, err
+        if np == 0 {
+            return m, errors.New(badGrammar)
         }
-        m.Port = int(p)
-    }
-    if !contains(TransportTypes, tokens[2]) {
-        return m, errors.New(badGrammar)
+        m.NumPorts = np
     }
     m.Type = tokens[0]
     m.Proto = tokens[2]
-    m.Fmt = tokens[3]
+    for _, f := range tokens[3:] {
+        if f == "" {
+            return m, errors.New(badGrammar)
+        }
+        m.Formats = append(m.Formats, f)
+    }
     return m, nil
 }
+
+// The UnmarshalText methods below parse the value of the corresponding SDP
+// field, without its "x=" prefix.
+
+func (o *Origin) UnmarshalText(b []byte) error {
+    v, err := parseOrigin(string(b))
+    if err != nil {
+        return err
+    }
+    *o = v
+    return nil
+}
+
+func (e *Email) UnmarshalText(b []byte) error {
+    v, err := parseEmail(string(b))
+    if err != nil {
+        return err
+    }
+    *e = v
+    return nil
+}
+
+func (e *Phone) UnmarshalText(b []byte) error {
+    v, err := parsePhone(string(b))
+    if err != nil {
+        return err
+    }
+    *e = v
+    return nil
+}
+
+func (c *Connection) UnmarshalText(b []byte) error {
+    v, err := parseConnection(string(b))
+    if err != nil {
+        return err
+    }
+    *c = v
+    return nil
+}
+
+func (bw *Bandwidth) UnmarshalText(b []byte) error {
+    v, err := parseBandwidth(string(b))
+    if err != nil {
+        return err
+    }
+    *bw = v
+    return nil
+}
+
+func (k *Key) UnmarshalText(b []byte) error {
+    v, err := parseKey(string(b))
+    if err != nil {
+        return err
+    }
+    *k = v
+    return nil
+}
+
+func (a *Attribute) UnmarshalText(b []byte) error {
+    v, err := parseAttribute(string(b))
+    if err != nil {
+        return err
+    }
+    *a = v
+    return nil
+}
+
+// UnmarshalText parses an m= field into m. Fields that belong to other
+// lines of the media section are left untouched.
+func (m *MediaDescription) UnmarshalText(b []byte) error {
+    v, err := parseMedia(string(b))
+    if err != nil {
+        return err
+    }
+    m.Type = v.Type
+    m.Port = v.Port
+    m.NumPorts = v.NumPorts
+    m.Proto = v.Proto
+    m.Formats = v.Formats
+    return nil
+}
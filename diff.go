@@ -0,0 +1,194 @@
+package sdp
+
+import (
+    "strconv"
+    "strings"
+    )
+
+// ChangeKind classifies a Change reported by Diff.
+type ChangeKind int
+
+const (
+    VersionChanged ChangeKind = iota
+    MediaAdded
+    MediaRemoved
+    MediaRejected
+    PortChanged
+    ConnectionChanged
+    CodecsChanged
+    DirectionChanged
+    IceRestart
+    FingerprintChanged
+    )
+
+var changeKindNames = []string{
+    "version changed",
+    "media added",
+    "media removed",
+    "media rejected",
+    "port changed",
+    "connection changed",
+    "codecs changed",
+    "direction changed",
+    "ICE restart",
+    "fingerprint changed",
+}
+
+func (k ChangeKind) String() string {
+    if k < 0 || int(k) >= len(changeKindNames) {
+        return "change " + strconv.Itoa(int(k))
+    }
+    return changeKindNames[k]
+}
+
+// Change is a single semantic difference between two session descriptions.
+// Index is the m-line index in the new description (in the old one for
+// MediaRemoved), or -1 for session level changes. Mid is the a=mid of the
+// section when it has one. For IceRestart Old and New hold the ICE
+// username fragments; a restart may change only the password, which is
+// never reported.
+type Change struct {
+    Kind  ChangeKind
+    Index int
+    Mid   string
+    Old   string
+    New   string
+}
+
+// String formats c for logs, e.g. "m[1] mid=video: direction changed: sendrecv -> sendonly".
+func (c Change) String() string {
+    s := "session"
+    if c.Index >= 0 {
+        s = "m[" + strconv.Itoa(c.Index) + "]"
+        if c.Mid != "" {
+            s += " mid=" + c.Mid
+        }
+    }
+    s += ": " + c.Kind.String()
+    switch {
+    case c.Kind == MediaAdded:
+        s += ": " + c.New
+    case c.Kind == MediaRemoved:
+        s += ": " + c.Old
+    case c.Kind == IceRestart && c.Old == c.New:
+        s += ": password changed"
+    case c.Old != "" || c.New != "":
+        s += ": " + quoteEmpty(c.Old) + " -> " + quoteEmpty(c.New)
+    }
+    return s
+}
+
+func quoteEmpty(s string) string {
+    if s == "" {
+        return `""`
+    }
+    return s
+}
+
+// FormatChanges returns changes one per line, as printed by Change.String.
+func FormatChanges(changes []Change) string {
+    s := ""
+    for _, c := range changes {
+        s += c.String() + "\n"
+    }
+    return s
+}
+
+// Diff reports what changed from a to b, typically an offer and the
+// re-offer that follows it. Media sections are matched by mid when both
+// carry one and by m-line index otherwise.
+func Diff(a, b *SessionDescription) []Change {
+    var changes []Change
+    if a.Origin.SessionVersion != b.Origin.SessionVersion {
+        changes = append(changes, Change{VersionChanged, -1, "", a.Origin.SessionVersion, b.Origin.SessionVersion})
+    }
+    matched := make([]bool, len(a.MediaDescriptions))
+    for j := range b.MediaDescriptions {
+        nm := &b.MediaDescriptions[j]
+        i := matchMedia(a, nm, j)
+        if i == -1 || matched[i] {
+            changes = append(changes, Change{MediaAdded, j, nm.Mid(), "", nm.String()})
+            continue
+        }
+        matched[i] = true
+        changes = append(changes, diffMedia(a, &a.MediaDescriptions[i], b, nm, j)...)
+    }
+    for i, ok := range matched {
+        if !ok {
+            om := &a.MediaDescriptions[i]
+            changes = append(changes, Change{MediaRemoved, i, om.Mid(), om.String(), ""})
+        }
+    }
+    return changes
+}
+
+// matchMedia returns the index in a of the section corresponding to m, which
+// is at index j in its own description.
+func matchMedia(a *SessionDescription, m *MediaDescription, j int) int {
+    if mid := m.Mid(); mid != "" {
+        for i := range a.MediaDescriptions {
+            if a.MediaDescriptions[i].Mid() == mid {
+                return i
+            }
+        }
+    }
+    if j < len(a.MediaDescriptions) && a.MediaDescriptions[j].Type == m.Type {
+        if mid := a.MediaDescriptions[j].Mid(); mid == "" || m.Mid() == "" {
+            return j
+        }
+    }
+    return -1
+}
+
+func diffMedia(a *SessionDescription, om *MediaDescription, b *SessionDescription, nm *MediaDescription, j int) []Change {
+    var changes []Change
+    add := func(kind ChangeKind, old, new string) {
+        if old != new {
+            changes = append(changes, Change{kind, j, nm.Mid(), old, new})
+        }
+    }
+    if om.Port != 0 && nm.Port == 0 {
+        changes = append(changes, Change{MediaRejected, j, nm.Mid(), "", ""})
+        return changes
+    }
+    add(PortChanged, portString(om), portString(nm))
+    add(ConnectionChanged, a.MediaConnection(om).String(), b.MediaConnection(nm).String())
+    add(CodecsChanged, strings.Join(codecList(om), " "), strings.Join(codecList(nm), " "))
+    add(DirectionChanged, a.Direction(om), b.Direction(nm))
+    oufrag, _ := a.MediaAttribute(om, "ice-ufrag")
+    opwd, _ := a.MediaAttribute(om, "ice-pwd")
+    nufrag, _ := b.MediaAttribute(nm, "ice-ufrag")
+    npwd, _ := b.MediaAttribute(nm, "ice-pwd")
+    if oufrag != nufrag || opwd != npwd {
+        changes = append(changes, Change{IceRestart, j, nm.Mid(), oufrag, nufrag})
+    }
+    ofp, _ := a.MediaAttribute(om, "fingerprint")
+    nfp, _ := b.MediaAttribute(nm, "fingerprint")
+    add(FingerprintChanged, ofp, nfp)
+    return changes
+}
+
+func portString(m *MediaDescription) string {
+    s := strconv.Itoa(m.Port)
+    if m.NumPorts > 0 {
+        s += "/" + strconv.Itoa(m.NumPorts)
+    }
+    return s
+}
+
+// codecList describes each format of m, with its rtpmap encoding when
+// there is one, e.g. "0" or "99:h263-1998/90000".
+func codecList(m *MediaDescription) []string {
+    var codecs []string
+    rtpmaps := m.AttributeValues("rtpmap")
+    for _, f := range m.Formats {
+        c := f
+        for _, r := range rtpmaps {
+            if tokens := strings.Fields(r); len(tokens) == 2 && tokens[0] == f {
+                c += ":" + tokens[1]
+            }
+        }
+        codecs = append(codecs, c)
+    }
+    return codecs
+}
@@ -0,0 +1,70 @@
+package sdp
+
+import (
+    "strings"
+    "testing"
+    )
+
+var offer = `v=0
+o=- 20518 0 IN IP4 203.0.113.1
+s=-
+c=IN IP4 203.0.113.1
+t=0 0
+a=ice-ufrag:F7gI
+a=ice-pwd:x9cml/YzichV2+XlhiMu8g
+a=fingerprint:sha-256 42:89:c5:c6:55:9d:6e:c8
+m=audio 54400 RTP/AVP 0
+a=mid:a
+a=sendrecv
+m=video 55400 RTP/AVP 99
+a=mid:v
+a=rtpmap:99 h263-1998/90000
+a=sendrecv
+`
+
+func TestDiff(t *testing.T) {
+    a, err := Decode(offer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, err := Decode(offer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if changes := Diff(a, b); len(changes) != 0 {
+        t.Errorf("unexpected changes:\n%s", FormatChanges(changes))
+    }
+    b.Origin.SessionVersion = "1"
+    b.Attributes[0].Value = "Ahs8"
+    b.MediaDescriptions[0].Port = 0
+    b.MediaDescriptions[1].Attributes[2].Key = "sendonly"
+    b.MediaDescriptions[1].Attributes[1].Value = "99 H264/90000"
+    b.MediaDescriptions[1].Connections = []Connection{Connection{"IN", "IP4", "198.51.100.7"}}
+    b.MediaDescriptions = append(b.MediaDescriptions, MediaDescription{Type: "text", Port: 9, Proto: "RTP/AVP", Formats: []string{"98"}, Attributes: []Attribute{Attribute{"mid", "t"}}})
+    want := []string{
+        "session: version changed: 0 -> 1",
+        "m[0] mid=a: media rejected",
+        "m[1] mid=v: connection changed: IN IP4 203.0.113.1 -> IN IP4 198.51.100.7",
+        "m[1] mid=v: codecs changed: 99:h263-1998/90000 -> 99:H264/90000",
+        "m[1] mid=v: direction changed: sendrecv -> sendonly",
+        "m[1] mid=v: ICE restart: F7gI -> Ahs8",
+        "m[2] mid=t: media added: text 9 RTP/AVP 98",
+    }
+    got := strings.Split(strings.TrimSpace(FormatChanges(Diff(a, b))), "\n")
+    if strings.Join(got, "\n") != strings.Join(want, "\n") {
+        t.Errorf("wrong changes:\n%s", strings.Join(got, "\n"))
+    }
+    b.MediaDescriptions = b.MediaDescriptions[1:]
+    changes := Diff(a, b)
+    if last := changes[len(changes)-1]; last.Kind != MediaRemoved || last.Index != 0 || last.Mid != "a" || last.String() != "m[0] mid=a: media removed: audio 54400 RTP/AVP 0" {
+        t.Errorf("wrong removal: %v", last)
+    }
+
+    // A restart changing only the password is reported with both.
+    b = a.Clone()
+    b.Attributes[1].Value = "b5kR0bMmjaq6OWTC4mi3kA"
+    changes = Diff(a, b)
+    if len(changes) != 2 || changes[0].String() != "m[0] mid=a: ICE restart: password changed" || strings.Contains(FormatChanges(changes), "b5kR") {
+        t.Errorf("password restart: %v", changes)
+    }
+}
@@ -1,152 +1,365 @@
 package sdp
 
 import (
+    "bytes"
+    "errors"
     "fmt"
+    "io"
+    "sort"
     "strconv"
+    "strings"
+    "time"
     )
 
+const (
+    CRLF string = "\r\n"
+    LF string = "\n"
+    )
+
+const (
+    badLineEnding string = "unsupported line ending"
+    )
+
+// AttributeOrder selects the order in which a= lines are written.
+type AttributeOrder int
+
+const (
+    // PreserveOrder writes attributes in the order they appear in the struct.
+    PreserveOrder AttributeOrder = iota
+    // SortedOrder writes attributes sorted by key, keeping the relative
+    // order of attributes sharing a key.
+    SortedOrder
+    )
+
+// EncodeOptions controls how a SessionDescription is written. The zero value
+// produces RFC 4566 output: CRLF line endings, compact units in r= and z=
+// lines, attributes in struct order and empty optional fields omitted.
+type EncodeOptions struct {
+    // LineEnding is CRLF or LF. Empty means CRLF.
+    LineEnding string
+    // RawSeconds writes r= and z= durations in seconds (604800 instead of
+    // 7d). By default the largest unit that represents a duration exactly
+    // is used.
+    RawSeconds bool
+    // AttributeOrder is applied to session and media level attributes.
+    AttributeOrder AttributeOrder
+    // KeepEmpty writes the optional i= and u= lines even when empty.
+    KeepEmpty bool
+}
+
+// Encoder writes session descriptions to an io.Writer.
+type Encoder struct {
+    w io.Writer
+    opts EncodeOptions
+}
+
+// NewEncoder returns an Encoder writing to w with the given options.
+func NewEncoder(w io.Writer, opts EncodeOptions) *Encoder {
+    return &Encoder{w, opts}
+}
+
+// Encode writes sd to the underlying writer. Nothing is written if sd
+// cannot be encoded.
+func (e *Encoder) Encode(sd *SessionDescription) error {
+    b, err := sd.encode(e.opts)
+    if err != nil {
+        return err
+    }
+    _, err = e.w.Write(b)
+    return err
+}
+
+// Encode returns sd as an SDP string using the default options.
 func (sd *SessionDescription) Encode() (string, error) {
-    str := ""
+    return sd.EncodeWithOptions(EncodeOptions{})
+}
+
+// EncodeWithOptions returns sd as an SDP string using opts.
+func (sd *SessionDescription) EncodeWithOptions(opts EncodeOptions) (string, error) {
+    b, err := sd.encode(opts)
+    if err != nil {
+        return "", err
+    }
+    return string(b), nil
+}
+
+type lineWriter struct {
+    buf bytes.Buffer
+    opts EncodeOptions
+}
+
+func (l *lineWriter) line(s string) {
+    l.buf.WriteString(s)
+    l.buf.WriteString(l.opts.LineEnding)
+}
+
+func (l *lineWriter) field(typ string, value string) {
+    l.line(typ + "=" + value)
+}
+
+func (l *lineWriter) attributes(attrs []Attribute) {
+    if l.opts.AttributeOrder == SortedOrder {
+        sorted := make([]Attribute, len(attrs))
+        copy(sorted, attrs)
+        sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+        attrs = sorted
+    }
+    for _, attr := range attrs {
+        l.field("a", attr.String())
+    }
+}
+
+func (sd *SessionDescription) encode(opts EncodeOptions) ([]byte, error) {
+    if opts.LineEnding == "" {
+        opts.LineEnding = CRLF
+    }
+    if opts.LineEnding != CRLF && opts.LineEnding != LF {
+        return nil, errors.New(badLineEnding)
+    }
+    l := &lineWriter{opts: opts}
     // Version
-    str += "v=" + strconv.FormatInt(int64(sd.Version),10) + "\n"
+    l.field("v", strconv.FormatInt(int64(sd.Version),10))
     // Origin
-    str += "o=" + sd.Origin.String()  + "\n"
+    l.field("o", sd.Origin.String())
     // Session Name
-    str += "s=" + sd.SessionName  + "\n"
+    l.field("s", sd.SessionName)
     // Info
-    if sd.Info != "" {
-        str += "i=" + sd.Info  + "\n"
+    if sd.Info != "" || opts.KeepEmpty {
+        l.field("i", sd.Info)
     }
     // URI
-    if sd.Uri != "" {
-        str += "u=" + sd.Uri  + "\n"
+    if sd.Uri != "" || opts.KeepEmpty {
+        l.field("u", sd.Uri)
     }
     // Emails
     for _, email := range sd.Emails {
-        str += email.String() + "\n"
+        l.field("e", email.String())
     }
     // Phone Numbers
     for _, phone := range sd.Phones {
-        str += phone.String() + "\n"
+        l.field("p", phone.String())
     }
     // Connection
-    if sd.Connection.String() != new(Connection).String() {
-        str += sd.Connection.String() + "\n"
+    if sd.Connection != (Connection{}) {
+        l.field("c", sd.Connection.String())
     }
     // Bandwidths
     for _, bandwidth := range sd.Bandwidths {
-        str += bandwidth.String() + "\n"
+        l.field("b", bandwidth.String())
     }
     // Times
-    for _, time := range sd.Times {
-        str += time.String() + "\n"
+    for _, t := range sd.Times {
+        if err := l.time(&t); err != nil {
+            return nil, err
+        }
     }
     // Key
-    if sd.Key.String() != new(Key).String() {
-        str += sd.Key.String() + "\n"
-    }
-    // Addributes
-    for _,attr := range sd.Attributes {
-        str += attr.String() + "\n"
+    if sd.Key != (Key{}) {
+        l.field("k", sd.Key.String())
     }
+    // Attributes
+    l.attributes(sd.Attributes)
     // Media Descriptions
     for _, md := range sd.MediaDescriptions {
-        str += md.String() + "\n"
+        l.media(&md)
+    }
+    return l.buf.Bytes(), nil
+}
+
+func (l *lineWriter) time(t *TimeDescription) error {
+    l.field("t", t.String())
+    for _, r := range t.Repeats {
+        durations := append([]time.Duration{r.Interval, r.Active}, r.Offsets...)
+        s := ""
+        for i, d := range durations {
+            if err := checkDuration(d, false); err != nil {
+                return err
+            }
+            if i > 0 {
+                s += " "
+            }
+            s += formatDuration(d, !l.opts.RawSeconds)
+        }
+        l.field("r", s)
+    }
+    if len(t.Zones) > 0 {
+        s := ""
+        for i, z := range t.Zones {
+            if err := checkDuration(z.Offset, true); err != nil {
+                return err
+            }
+            if i > 0 {
+                s += " "
+            }
+            s += strconv.FormatInt(ntpTime(z.Time), 10) + " " + formatDuration(z.Offset, !l.opts.RawSeconds)
+        }
+        l.field("z", s)
+    }
+    return nil
+}
+
+func (l *lineWriter) media(m *MediaDescription) {
+    l.field("m", m.String())
+    if m.Info != "" || l.opts.KeepEmpty {
+        l.field("i", m.Info)
     }
-    return str, nil
+    for _, c := range m.Connections {
+        l.field("c", c.String())
+    }
+    for _, b := range m.Bandwidths {
+        l.field("b", b.String())
+    }
+    if m.Key != (Key{}) {
+        l.field("k", m.Key.String())
+    }
+    l.attributes(m.Attributes)
+}
+
+// ntpTime converts t to seconds since the NTP epoch. The zero time maps to
+// 0, which SDP uses for unbounded sessions.
+func ntpTime(t time.Time) int64 {
+    if t.IsZero() {
+        return 0
+    }
+    return t.Unix() + ntpUnix
+}
+
+// checkDuration reports whether d can be written as an SDP typed-time.
+// Only zone offsets may be negative.
+func checkDuration(d time.Duration, negative bool) error {
+    if d % time.Second != 0 || (d < 0 && !negative) {
+        return errors.New(badDuration)
+    }
+    return nil
 }
 
-func (o *Origin) String() string {
+// formatDuration writes d in whole seconds, or with the largest of the
+// d, h and m units that represents it exactly when compact is set.
+func formatDuration(d time.Duration, compact bool) string {
+    secs := int64(d / time.Second)
+    if compact && secs != 0 {
+        for _, u := range durationUnits {
+            if secs % u.seconds == 0 {
+                return strconv.FormatInt(secs / u.seconds, 10) + u.suffix
+            }
+        }
+    }
+    return strconv.FormatInt(secs, 10)
+}
+
+var durationUnits = []struct {
+    suffix string
+    seconds int64
+}{
+    {"d", 86400},
+    {"h", 3600},
+    {"m", 60},
+}
+
+// The String methods below return the value of the corresponding SDP field,
+// without its "x=" prefix. MarshalText returns the same value.
+
+func (o Origin) String() string {
     return fmt.Sprintf("%s %s %s %s %s %s", o.Username, o.SessionId, o.SessionVersion, o.NetType, o.AddrType, o.UnicastAddr)
 }
 
-func (e *Email) String() string {
-    email := "e=" + e.Address
+func (o Origin) MarshalText() ([]byte, error) {
+    return []byte(o.String()), nil
+}
+
+func (e Email) String() string {
+    email := e.Address
     if e.Name != "" {
         email += " (" + e.Name + ")"
     }
     return email
 }
 
-func (e *Phone) String() string {
-    phone := "p=" + e.Address
+func (e Email) MarshalText() ([]byte, error) {
+    return []byte(e.String()), nil
+}
+
+func (e Phone) String() string {
+    phone := e.Address
     if e.Name != "" {
         phone += " (" + e.Name + ")"
     }
     return phone
 }
 
-func (c *Connection) String() string {
-    return "c=" + c.NetType + " " + c.AddrType + " " + c.Address
+func (e Phone) MarshalText() ([]byte, error) {
+    return []byte(e.String()), nil
 }
 
-func (b *Bandwidth) String() string {
-    return "b=" + b.Type + ":" + b.Bandwidth
+func (c Connection) String() string {
+    return c.NetType + " " + c.AddrType + " " + c.Address
 }
 
-func (t *TimeDescription) String() string {
-    s := "t=" + strconv.FormatInt(t.Start.Unix(), 10)
-    s += " " + strconv.FormatInt(t.Stop.Unix(), 10)
-    for _,r := range t.Repeats {
-        s += "\n" +  r.String()
-    }
-    if t.Zones != nil {
-        s += "\nz="
-        for _,z := range t.Zones {
-            s += z.String() + " "
-        }
-    }
-    return s
+func (c Connection) MarshalText() ([]byte, error) {
+    return []byte(c.String()), nil
+}
+
+func (b Bandwidth) String() string {
+    return b.Type + ":" + b.Bandwidth
 }
 
-func (r *Repeat) String() string {
-    s := "r=" + strconv.FormatInt(int64(r.Interval.Seconds()), 10)
-    s += " " + strconv.FormatInt(int64(r.Active.Seconds()), 10)
+func (b Bandwidth) MarshalText() ([]byte, error) {
+    return []byte(b.String()), nil
+}
+
+func (t TimeDescription) String() string {
+    return strconv.FormatInt(ntpTime(t.Start), 10) + " " + strconv.FormatInt(ntpTime(t.Stop), 10)
+}
+
+func (r Repeat) String() string {
+    s := formatDuration(r.Interval, true)
+    s += " " + formatDuration(r.Active, true)
     for _, o := range r.Offsets {
-        s += " " + strconv.FormatInt(int64(o.Seconds()), 10)
+        s += " " + formatDuration(o, true)
     }
     return s
 }
 
-func (z *Zone) String() string {
-    return strconv.FormatInt(z.Time.Unix() + ntpUnix, 10) + " " + strconv.FormatInt(int64(z.Offset.Seconds()), 10)
+func (z Zone) String() string {
+    return strconv.FormatInt(ntpTime(z.Time), 10) + " " + formatDuration(z.Offset, true)
 }
 
-func (k *Key) String() string {
+func (k Key) String() string {
     if k.Key != "" {
-        return "k=" + k.Method + ":" + k.Key
+        return k.Method + ":" + k.Key
     }
-    return "k=" + k.Method
+    return k.Method
+}
+
+func (k Key) MarshalText() ([]byte, error) {
+    return []byte(k.String()), nil
 }
 
-func (a *Attribute) String() string {
+func (a Attribute) String() string {
     if a.Value != "" {
-        return "a=" + a.Key  + ":" + a.Value
+        return a.Key  + ":" + a.Value
     }
     return a.Key
 }
 
-func (m *MediaDescription) String() string {
-    s := ""
+func (a Attribute) MarshalText() ([]byte, error) {
+    return []byte(a.String()), nil
+}
+
+// String returns the m= field of m. The other lines of the media section
+// are written by Encode.
+func (m MediaDescription) String() string {
+    s := m.Type + " " + strconv.FormatInt(int64(m.Port), 10)
     if m.NumPorts > 0 {
-        s = "m=" + m.Type + " " + strconv.FormatInt(int64(m.Port), 10) + "/" + strconv.FormatInt(int64(m.NumPorts), 10) + " " + m.Proto + " " + m.Fmt
-    } else {
-        s = "m=" + m.Type + " " + strconv.FormatInt(int64(m.Port), 10) + " " + m.Proto + " " + m.Fmt
+        s += "/" + strconv.FormatInt(int64(m.NumPorts), 10)
     }
-    if m.Info != "" {
-        s += "\ni=" + m.Info
-    }
-    for _, c := range m.Connections {
-        s += "\n" + c.String()
-    }
-    for _, b := range m.Bandwidths {
-        s += "\n" + b.String()
-    }
-    if m.Key.String() != new(Key).String() {
-        s += "\n" + m.Key.String()
-    }
-    for _, attr := range m.Attributes {
-        s += "\n" + attr.String()
+    s += " " + m.Proto
+    if len(m.Formats) > 0 {
+        s += " " + strings.Join(m.Formats, " ")
     }
     return s
 }
+
+func (m MediaDescription) MarshalText() ([]byte, error) {
+    return []byte(m.String()), nil
+}
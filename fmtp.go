@@ -0,0 +1,441 @@
+package sdp
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    )
+
+// parseFmtpParams splits an fmtp value into its semicolon separated
+// parameters. Parameters without "=" map to an empty value.
+func parseFmtpParams(s string) map[string]string {
+    params := map[string]string{}
+    for _, kv := range strings.Split(s, ";") {
+        if k, v, _ := strings.Cut(strings.TrimSpace(kv), "="); k != "" {
+            params[k] = v
+        }
+    }
+    return params
+}
+
+// paramInt parses the integer parameter key, returning def when absent.
+func paramInt(params map[string]string, key string, def int) (int, error) {
+    v, ok := params[key]
+    if !ok {
+        return def, nil
+    }
+    return strconv.Atoi(v)
+}
+
+// paramBool parses the 0/1 parameter key, false when absent.
+func paramBool(params map[string]string, key string) (bool, error) {
+    switch params[key] {
+    case "", "0":
+        return false, nil
+    case "1":
+        return true, nil
+    }
+    return false, errors.New(badGrammar)
+}
+
+// OpusFmtp are the Opus fmtp parameters (RFC 7587). Integer fields are 0
+// when absent.
+type OpusFmtp struct {
+    MaxPlaybackRate     int
+    SpropMaxCaptureRate int
+    MaxAverageBitrate   int
+    MinPtime            int
+    Stereo              bool
+    SpropStereo         bool
+    CBR                 bool
+    UseInbandFEC        bool
+    UseDTX              bool
+}
+
+// ParseOpusFmtp parses an Opus fmtp value.
+func ParseOpusFmtp(s string) (OpusFmtp, error) {
+    params := parseFmtpParams(s)
+    var f OpusFmtp
+    var err error
+    for _, p := range []struct {
+        key string
+        v *int
+    }{
+        {"maxplaybackrate", &f.MaxPlaybackRate},
+        {"sprop-maxcapturerate", &f.SpropMaxCaptureRate},
+        {"maxaveragebitrate", &f.MaxAverageBitrate},
+        {"minptime", &f.MinPtime},
+    } {
+        if *p.v, err = paramInt(params, p.key, 0); err != nil {
+            return OpusFmtp{}, err
+        }
+    }
+    for _, p := range []struct {
+        key string
+        v *bool
+    }{
+        {"stereo", &f.Stereo},
+        {"sprop-stereo", &f.SpropStereo},
+        {"cbr", &f.CBR},
+        {"useinbandfec", &f.UseInbandFEC},
+        {"usedtx", &f.UseDTX},
+    } {
+        if *p.v, err = paramBool(params, p.key); err != nil {
+            return OpusFmtp{}, err
+        }
+    }
+    return f, nil
+}
+
+func (f OpusFmtp) String() string {
+    var s []string
+    num := func(key string, v int) {
+        if v > 0 {
+            s = append(s, key + "=" + strconv.Itoa(v))
+        }
+    }
+    flag := func(key string, v bool) {
+        if v {
+            s = append(s, key + "=1")
+        }
+    }
+    num("maxplaybackrate", f.MaxPlaybackRate)
+    num("sprop-maxcapturerate", f.SpropMaxCaptureRate)
+    num("maxaveragebitrate", f.MaxAverageBitrate)
+    num("minptime", f.MinPtime)
+    flag("stereo", f.Stereo)
+    flag("sprop-stereo", f.SpropStereo)
+    flag("cbr", f.CBR)
+    flag("useinbandfec", f.UseInbandFEC)
+    flag("usedtx", f.UseDTX)
+    return strings.Join(s, ";")
+}
+
+// H264Profile is an H.264 profile as identified by profile-level-id.
+type H264Profile int
+
+const (
+    H264ConstrainedBaseline H264Profile = iota
+    H264Baseline
+    H264Main
+    H264ConstrainedHigh
+    H264High
+    )
+
+// h264Profiles maps profile_idc and profile-iop bit patterns to profiles,
+// as in RFC 6184 table 5; 'x' bits are ignored.
+var h264Profiles = []struct {
+    idc     byte
+    iop     string
+    profile H264Profile
+}{
+    {0x42, "x1xx0000", H264ConstrainedBaseline},
+    {0x4D, "1xxx0000", H264ConstrainedBaseline},
+    {0x58, "11xx0000", H264ConstrainedBaseline},
+    {0x42, "x0xx0000", H264Baseline},
+    {0x58, "10xx0000", H264Baseline},
+    {0x4D, "0x0x0000", H264Main},
+    {0x64, "00000000", H264High},
+    {0x64, "00001100", H264ConstrainedHigh},
+}
+
+// DefaultH264ProfileLevelID is the profile-level-id assumed when absent:
+// Baseline profile, level 1.
+const DefaultH264ProfileLevelID = "42000a"
+
+// H264Fmtp are the H.264 fmtp parameters (RFC 6184) that matter for
+// negotiation.
+type H264Fmtp struct {
+    ProfileLevelID        string
+    PacketizationMode     int
+    LevelAsymmetryAllowed bool
+}
+
+// ParseH264Fmtp parses an H.264 fmtp value.
+func ParseH264Fmtp(s string) (H264Fmtp, error) {
+    return h264Fmtp(parseFmtpParams(s))
+}
+
+func h264Fmtp(params map[string]string) (H264Fmtp, error) {
+    f := H264Fmtp{ProfileLevelID: params["profile-level-id"]}
+    if f.ProfileLevelID == "" {
+        f.ProfileLevelID = DefaultH264ProfileLevelID
+    }
+    if _, err := strconv.ParseUint(f.ProfileLevelID, 16, 24); err != nil || len(f.ProfileLevelID) != 6 {
+        return H264Fmtp{}, errors.New(badGrammar)
+    }
+    var err error
+    if f.PacketizationMode, err = paramInt(params, "packetization-mode", 0); err != nil {
+        return H264Fmtp{}, err
+    }
+    if f.LevelAsymmetryAllowed, err = paramBool(params, "level-asymmetry-allowed"); err != nil {
+        return H264Fmtp{}, err
+    }
+    return f, nil
+}
+
+// Profile returns the profile of f, or false when profile-level-id names
+// a profile outside the table of RFC 6184.
+func (f H264Fmtp) Profile() (H264Profile, bool) {
+    n, _ := strconv.ParseUint(f.ProfileLevelID, 16, 24)
+    idc, iop := byte(n >> 16), byte(n >> 8)
+    for _, p := range h264Profiles {
+        if p.idc != idc {
+            continue
+        }
+        match := true
+        for i, c := range p.iop {
+            bit := iop >> (7 - i) & 1
+            if c != 'x' && bit != byte(c - '0') {
+                match = false
+            }
+        }
+        if match {
+            return p.profile, true
+        }
+    }
+    return 0, false
+}
+
+// Level returns the level_idc of f, e.g. 31 for level 3.1.
+func (f H264Fmtp) Level() int {
+    n, _ := strconv.ParseUint(f.ProfileLevelID, 16, 24)
+    return int(n & 0xff)
+}
+
+// withLevel returns f with its level_idc replaced.
+func (f H264Fmtp) withLevel(level int) H264Fmtp {
+    f.ProfileLevelID = f.ProfileLevelID[:4] + strconv.FormatUint(uint64(level) | 0x100, 16)[1:]
+    return f
+}
+
+func (f H264Fmtp) String() string {
+    s := "profile-level-id=" + f.ProfileLevelID
+    if f.LevelAsymmetryAllowed {
+        s = "level-asymmetry-allowed=1;" + s
+    }
+    if f.PacketizationMode != 0 {
+        s += ";packetization-mode=" + strconv.Itoa(f.PacketizationMode)
+    }
+    return s
+}
+
+// H264Compatible reports whether a and b can be used together: the same
+// profile and packetization mode (RFC 6184 section 8.1). Levels may
+// differ; the answer then uses the lower one.
+func H264Compatible(a, b H264Fmtp) bool {
+    pa, oka := a.Profile()
+    pb, okb := b.Profile()
+    return oka && okb && pa == pb && a.PacketizationMode == b.PacketizationMode
+}
+
+// VP9Fmtp are the VP9 fmtp parameters.
+type VP9Fmtp struct {
+    ProfileID int
+}
+
+// ParseVP9Fmtp parses a VP9 fmtp value.
+func ParseVP9Fmtp(s string) (VP9Fmtp, error) {
+    id, err := paramInt(parseFmtpParams(s), "profile-id", 0)
+    return VP9Fmtp{id}, err
+}
+
+func (f VP9Fmtp) String() string {
+    return "profile-id=" + strconv.Itoa(f.ProfileID)
+}
+
+// AV1Fmtp are the AV1 fmtp parameters, with their defaults when absent:
+// profile 0, level-idx 5 and tier 0.
+type AV1Fmtp struct {
+    Profile  int
+    LevelIdx int
+    Tier     int
+}
+
+// ParseAV1Fmtp parses an AV1 fmtp value.
+func ParseAV1Fmtp(s string) (AV1Fmtp, error) {
+    params := parseFmtpParams(s)
+    var f AV1Fmtp
+    var err error
+    if f.Profile, err = paramInt(params, "profile", 0); err != nil {
+        return AV1Fmtp{}, err
+    }
+    if f.LevelIdx, err = paramInt(params, "level-idx", 5); err != nil {
+        return AV1Fmtp{}, err
+    }
+    if f.Tier, err = paramInt(params, "tier", 0); err != nil {
+        return AV1Fmtp{}, err
+    }
+    return f, nil
+}
+
+func (f AV1Fmtp) String() string {
+    return "level-idx=" + strconv.Itoa(f.LevelIdx) + ";profile=" + strconv.Itoa(f.Profile) + ";tier=" + strconv.Itoa(f.Tier)
+}
+
+// RTXFmtp are the RTX fmtp parameters (RFC 4588). RTXTime is 0 when absent.
+type RTXFmtp struct {
+    APT     int
+    RTXTime int
+}
+
+// ParseRTXFmtp parses an RTX fmtp value, which must carry apt.
+func ParseRTXFmtp(s string) (RTXFmtp, error) {
+    params := parseFmtpParams(s)
+    if _, ok := params["apt"]; !ok {
+        return RTXFmtp{}, errors.New(badGrammar)
+    }
+    var f RTXFmtp
+    var err error
+    if f.APT, err = paramInt(params, "apt", 0); err != nil {
+        return RTXFmtp{}, err
+    }
+    if f.RTXTime, err = paramInt(params, "rtx-time", 0); err != nil {
+        return RTXFmtp{}, err
+    }
+    return f, nil
+}
+
+func (f RTXFmtp) String() string {
+    s := "apt=" + strconv.Itoa(f.APT)
+    if f.RTXTime > 0 {
+        s += ";rtx-time=" + strconv.Itoa(f.RTXTime)
+    }
+    return s
+}
+
+// REDFmtp lists the payload types of the redundant encodings of audio RED
+// (RFC 2198), e.g. 111/111.
+type REDFmtp struct {
+    PayloadTypes []int
+}
+
+// ParseREDFmtp parses a RED fmtp value.
+func ParseREDFmtp(s string) (REDFmtp, error) {
+    var f REDFmtp
+    for _, t := range strings.Split(strings.TrimSpace(s), "/") {
+        pt, err := strconv.ParseUint(t, 10, 7)
+        if err != nil {
+            return REDFmtp{}, err
+        }
+        f.PayloadTypes = append(f.PayloadTypes, int(pt))
+    }
+    return f, nil
+}
+
+func (f REDFmtp) String() string {
+    var s []string
+    for _, pt := range f.PayloadTypes {
+        s = append(s, strconv.Itoa(pt))
+    }
+    return strings.Join(s, "/")
+}
+
+// TelephoneEventFmtp lists the DTMF and other events of telephone-event
+// (RFC 4733) as inclusive ranges.
+type TelephoneEventFmtp struct {
+    Events [][2]int
+}
+
+// ParseTelephoneEventFmtp parses a telephone-event fmtp value such as
+// 0-16,32. An empty value means events 0-15.
+func ParseTelephoneEventFmtp(s string) (TelephoneEventFmtp, error) {
+    var f TelephoneEventFmtp
+    if s = strings.TrimSpace(s); s == "" {
+        return TelephoneEventFmtp{[][2]int{{0, 15}}}, nil
+    }
+    for _, r := range strings.Split(s, ",") {
+        lo, hi, isRange := strings.Cut(strings.TrimSpace(r), "-")
+        a, err := strconv.ParseUint(lo, 10, 8)
+        if err != nil {
+            return TelephoneEventFmtp{}, err
+        }
+        b := a
+        if isRange {
+            if b, err = strconv.ParseUint(hi, 10, 8); err != nil {
+                return TelephoneEventFmtp{}, err
+            }
+        }
+        if b < a {
+            return TelephoneEventFmtp{}, errors.New(badGrammar)
+        }
+        f.Events = append(f.Events, [2]int{int(a), int(b)})
+    }
+    return f, nil
+}
+
+// Supports reports whether event is listed.
+func (f TelephoneEventFmtp) Supports(event int) bool {
+    for _, r := range f.Events {
+        if event >= r[0] && event <= r[1] {
+            return true
+        }
+    }
+    return false
+}
+
+func (f TelephoneEventFmtp) String() string {
+    var s []string
+    for _, r := range f.Events {
+        if r[0] == r[1] {
+            s = append(s, strconv.Itoa(r[0]))
+        } else {
+            s = append(s, strconv.Itoa(r[0]) + "-" + strconv.Itoa(r[1]))
+        }
+    }
+    return strings.Join(s, ",")
+}
+
+// CodecsCompatible reports whether a and b describe the same codec: the
+// same encoding name, ignoring case, clock rate and channel count, and fmtp
+// parameters that can interoperate. H.264 needs the same profile and
+// packetization mode, VP9 the same profile-id and AV1 the same profile.
+func CodecsCompatible(a, b RTPCodecParameters) bool {
+    if !sameCodec(RTPMap{0, encodingName(a), a.ClockRate, a.Channels}, RTPMap{0, encodingName(b), b.ClockRate, b.Channels}) {
+        return false
+    }
+    switch strings.ToLower(encodingName(a)) {
+    case "h264":
+        fa, erra := h264Fmtp(a.Parameters)
+        fb, errb := h264Fmtp(b.Parameters)
+        return erra == nil && errb == nil && H264Compatible(fa, fb)
+    case "vp9":
+        pa, erra := paramInt(a.Parameters, "profile-id", 0)
+        pb, errb := paramInt(b.Parameters, "profile-id", 0)
+        return erra == nil && errb == nil && pa == pb
+    case "av1":
+        pa, erra := paramInt(a.Parameters, "profile", 0)
+        pb, errb := paramInt(b.Parameters, "profile", 0)
+        return erra == nil && errb == nil && pa == pb
+    }
+    return true
+}
+
+// answerFmtp returns the fmtp an answer uses for offered codec o when the
+// answerer supports it as l, whose fmtp is fmtp. H.264 takes the lower
+// level unless both sides allow level asymmetry, RTX points to the offered
+// apt and RED to the offered redundant payload types.
+func answerFmtp(o, l RTPCodecParameters, offerFmtp, fmtp string) string {
+    switch strings.ToLower(encodingName(o)) {
+    case "h264":
+        fo, erro := h264Fmtp(o.Parameters)
+        fl, errl := h264Fmtp(l.Parameters)
+        if erro != nil || errl != nil || (fo.LevelAsymmetryAllowed && fl.LevelAsymmetryAllowed) {
+            return fmtp
+        }
+        if fo.Level() < fl.Level() {
+            fl = fl.withLevel(fo.Level())
+        }
+        params := parseFmtpParams(fmtp)
+        params["profile-level-id"] = fl.ProfileLevelID
+        return formatParameters(params)
+    case "red":
+        return offerFmtp
+    }
+    if apt := o.Parameters["apt"]; apt != "" {
+        params := parseFmtpParams(fmtp)
+        params["apt"] = apt
+        return formatParameters(params)
+    }
+    return fmtp
+}
@@ -0,0 +1,86 @@
+package sdp
+
+import (
+    "reflect"
+    "testing"
+    )
+
+func TestFmtp(t *testing.T) {
+    opus, err := ParseOpusFmtp("minptime=10;useinbandfec=1;stereo=1")
+    if err != nil || opus != (OpusFmtp{MinPtime: 10, Stereo: true, UseInbandFEC: true}) {
+        t.Errorf("opus: %+v %v", opus, err)
+    }
+    if _, err := ParseOpusFmtp("stereo=yes"); err == nil {
+        t.Error("expected error for bad opus flag")
+    }
+    for _, c := range []struct {
+        fmtp    string
+        profile H264Profile
+        level   int
+    }{
+        {"profile-level-id=42e01f;packetization-mode=1", H264ConstrainedBaseline, 31},
+        {"profile-level-id=42001f", H264Baseline, 31},
+        {"profile-level-id=4d0032", H264Main, 50},
+        {"profile-level-id=640c34", H264ConstrainedHigh, 52},
+        {"", H264Baseline, 10},
+    } {
+        f, err := ParseH264Fmtp(c.fmtp)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if p, ok := f.Profile(); !ok || p != c.profile || f.Level() != c.level {
+            t.Errorf("%q: got profile %v level %d", c.fmtp, p, f.Level())
+        }
+    }
+    a, _ := ParseH264Fmtp("profile-level-id=42e01f;packetization-mode=1")
+    b, _ := ParseH264Fmtp("profile-level-id=42e034;packetization-mode=1")
+    c, _ := ParseH264Fmtp("profile-level-id=42e01f")
+    if !H264Compatible(a, b) || H264Compatible(a, c) {
+        t.Error("wrong H.264 compatibility")
+    }
+    if rtx, err := ParseRTXFmtp("apt=96;rtx-time=3000"); err != nil || rtx != (RTXFmtp{96, 3000}) || rtx.String() != "apt=96;rtx-time=3000" {
+        t.Errorf("rtx: %+v %v", rtx, err)
+    }
+    if red, err := ParseREDFmtp("111/111"); err != nil || !reflect.DeepEqual(red.PayloadTypes, []int{111, 111}) {
+        t.Errorf("red: %+v %v", red, err)
+    }
+    te, err := ParseTelephoneEventFmtp("0-16,32")
+    if err != nil || !te.Supports(16) || te.Supports(17) || !te.Supports(32) || te.String() != "0-16,32" {
+        t.Errorf("telephone-event: %+v %v", te, err)
+    }
+    if av1, err := ParseAV1Fmtp("profile=1"); err != nil || av1 != (AV1Fmtp{1, 5, 0}) {
+        t.Errorf("av1: %+v %v", av1, err)
+    }
+}
+
+func TestAnswerFmtp(t *testing.T) {
+    offer := MediaDescription{Type: "video", Port: 9, Proto: "RTP/AVPF", Formats: []string{"100", "101", "102", "103"}, Attributes: []Attribute{
+        {"rtpmap", "100 H264/90000"},
+        {"fmtp", "100 profile-level-id=42001f;packetization-mode=1"},
+        {"rtpmap", "101 rtx/90000"},
+        {"fmtp", "101 apt=100"},
+        {"rtpmap", "102 H264/90000"},
+        {"fmtp", "102 profile-level-id=42e01f;packetization-mode=1"},
+        {"rtpmap", "103 rtx/90000"},
+        {"fmtp", "103 apt=102"},
+    }}
+    local := MediaDescription{Type: "video", Port: 9, Proto: "RTP/AVPF", Formats: []string{"96", "97"}, Attributes: []Attribute{
+        {"rtpmap", "96 H264/90000"},
+        {"fmtp", "96 profile-level-id=42e034;packetization-mode=1"},
+        {"rtpmap", "97 rtx/90000"},
+        {"fmtp", "97 apt=96;rtx-time=3000"},
+    }}
+    formats, attrs := negotiateFormats(&offer, &local)
+    if !reflect.DeepEqual(formats, []string{"102", "103"}) {
+        t.Fatalf("formats: %v", formats)
+    }
+    want := []Attribute{
+        {"rtpmap", "102 H264/90000"},
+        {"fmtp", "102 packetization-mode=1;profile-level-id=42e01f"},
+        {"rtpmap", "103 rtx/90000"},
+        {"fmtp", "103 apt=102;rtx-time=3000"},
+    }
+    if !reflect.DeepEqual(attrs, want) {
+        t.Errorf("attributes:\ngot  %v\nwant %v", attrs, want)
+    }
+}
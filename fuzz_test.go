@@ -0,0 +1,82 @@
+package sdp
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+    )
+
+// addSeeds adds the SDPs in testdata and the package test fixtures to the
+// seed corpus of f.
+func addSeeds(f *testing.F) {
+    files, err := filepath.Glob(filepath.Join("testdata", "*.sdp"))
+    if err != nil {
+        f.Fatal(err)
+    }
+    for _, name := range files {
+        b, err := os.ReadFile(name)
+        if err != nil {
+            f.Fatal(err)
+        }
+        f.Add(string(b))
+    }
+    for _, s := range []string{s1, s2, offer, capabilities} {
+        f.Add(s)
+    }
+}
+
+func FuzzDecode(f *testing.F) {
+    addSeeds(f)
+    f.Fuzz(func(t *testing.T, s string) {
+        _, err := Decode(s)
+        var perr *ParseError
+        if err != nil && !errors.As(err, &perr) {
+            t.Errorf("Decode returned %T, not a *ParseError: %v", err, err)
+        }
+    })
+}
+
+func FuzzRoundTrip(f *testing.F) {
+    addSeeds(f)
+    f.Fuzz(func(t *testing.T, s string) {
+        sd, err := Decode(s)
+        if err != nil {
+            return
+        }
+        enc, err := sd.Encode()
+        if err != nil {
+            t.Fatalf("Encode failed on decoded input: %v", err)
+        }
+        sd2, err := Decode(enc)
+        if err != nil {
+            t.Fatalf("Decode failed on encoded output: %v\n%q", err, enc)
+        }
+        if !reflect.DeepEqual(sd, sd2) {
+            t.Errorf("round trip mismatch:\n%+v\n%+v", sd, sd2)
+        }
+    })
+}
+
+func TestDecodeNoPanic(t *testing.T) {
+    for _, s := range []string{
+        "",
+        "\n\n",
+        "v",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\nr=7d 1h 0\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\nz=0 0\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\nt=0 0\nx=1\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\ne=a>b <c\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\ne=a )b (c\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\np=a>b<c\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\nt=0 0\nr=\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\nt=0 0\nr=1  0\n",
+        "v=0\no=- 1 1 IN IP4 0.0.0.0\ns=-\nt=0 0\nm=audio 0 RTP/AVP 0\nk=\n",
+    } {
+        _, err := Decode(s)
+        if _, ok := err.(*ParseError); err != nil && !ok {
+            t.Errorf("Decode(%q) returned %v", s, err)
+        }
+    }
+}
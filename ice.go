@@ -0,0 +1,75 @@
+package sdp
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    )
+
+// ICECandidate is the value of an a=candidate attribute (RFC 8839):
+// <foundation> <component> <transport> <priority> <address> <port> typ
+// <type> [raddr <address>] [rport <port>] followed by extensions such as
+// generation or tcptype.
+type ICECandidate struct {
+    Foundation     string
+    Component      int
+    Transport      string
+    Priority       uint32
+    Address        string
+    Port           int
+    Type           string
+    RelatedAddress string
+    RelatedPort    int
+    Extensions     []Attribute
+}
+
+// ParseICECandidate parses the value of an a=candidate attribute. A leading
+// "candidate:", as found in trickled candidates, is accepted.
+func ParseICECandidate(s string) (ICECandidate, error) {
+    tokens := strings.Fields(strings.TrimPrefix(s, "candidate:"))
+    if len(tokens) < 8 || tokens[6] != "typ" || len(tokens) % 2 != 0 {
+        return ICECandidate{}, errors.New(badGrammar)
+    }
+    c := ICECandidate{Foundation: tokens[0], Transport: tokens[2], Address: tokens[4], Type: tokens[7]}
+    var err error
+    if c.Component, err = strconv.Atoi(tokens[1]); err != nil {
+        return ICECandidate{}, err
+    }
+    priority, err := strconv.ParseUint(tokens[3], 10, 32)
+    if err != nil {
+        return ICECandidate{}, err
+    }
+    c.Priority = uint32(priority)
+    if c.Port, err = parsePort(tokens[5]); err != nil {
+        return ICECandidate{}, err
+    }
+    for i := 8; i < len(tokens); i += 2 {
+        switch tokens[i] {
+        case "raddr":
+            c.RelatedAddress = tokens[i+1]
+        case "rport":
+            if c.RelatedPort, err = parsePort(tokens[i+1]); err != nil {
+                return ICECandidate{}, err
+            }
+        default:
+            c.Extensions = append(c.Extensions, Attribute{tokens[i], tokens[i+1]})
+        }
+    }
+    return c, nil
+}
+
+// String returns the candidate as an a=candidate value.
+func (c ICECandidate) String() string {
+    s := c.Foundation + " " + strconv.Itoa(c.Component) + " " + c.Transport + " " +
+        strconv.FormatUint(uint64(c.Priority), 10) + " " + c.Address + " " + strconv.Itoa(c.Port) + " typ " + c.Type
+    if c.RelatedAddress != "" {
+        s += " raddr " + c.RelatedAddress
+    }
+    if c.RelatedAddress != "" || c.RelatedPort != 0 {
+        s += " rport " + strconv.Itoa(c.RelatedPort)
+    }
+    for _, e := range c.Extensions {
+        s += " " + e.Key + " " + e.Value
+    }
+    return s
+}
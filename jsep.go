@@ -0,0 +1,621 @@
+package sdp
+
+import (
+    "crypto/rand"
+    "encoding/base64"
+    "encoding/binary"
+    "errors"
+    "strconv"
+    "strings"
+    )
+
+const (
+    badSignalingState string = "invalid signaling state transition"
+    noCapability string = "no capability for media kind"
+    noMid string = "media description without mid"
+    badDirection string = "bad direction"
+    badAnswer string = "answer does not match offer"
+    noDescription string = "no session description"
+    )
+
+// SignalingState is the JSEP signaling state of a JSEPSession (RFC 8829).
+type SignalingState int
+
+const (
+    Stable SignalingState = iota
+    HaveLocalOffer
+    HaveRemoteOffer
+    HaveLocalPranswer
+    HaveRemotePranswer
+    )
+
+var signalingStateNames = []string{
+    "stable",
+    "have-local-offer",
+    "have-remote-offer",
+    "have-local-pranswer",
+    "have-remote-pranswer",
+}
+
+func (s SignalingState) String() string {
+    if s < 0 || int(s) >= len(signalingStateNames) {
+        return "state " + strconv.Itoa(int(s))
+    }
+    return signalingStateNames[s]
+}
+
+// DescriptionType is the type of a description passed to
+// SetLocalDescription and SetRemoteDescription.
+type DescriptionType int
+
+const (
+    OfferType DescriptionType = iota
+    PranswerType
+    AnswerType
+    RollbackType
+    )
+
+// Transceiver is a media source and sink negotiated in one m-section. Mid is
+// empty until the transceiver is associated with an m-section, and
+// CurrentDirection until a negotiation including it completes.
+type Transceiver struct {
+    Mid              string
+    Kind             string
+    Direction        string
+    CurrentDirection string
+    Stopped          bool
+    capability       *MediaDescription
+}
+
+// Stop marks t stopped. Its m-section is rejected in the next offer or
+// answer and recycled once that negotiation completes.
+func (t *Transceiver) Stop() {
+    t.Stopped = true
+}
+
+// JSEPConfig configures a JSEPSession.
+type JSEPConfig struct {
+    // Capabilities has one media section per supported kind, listing its
+    // protocol, formats and attributes such as rtpmap, fmtp, rtcp-fb or
+    // sctp-port. It is used both to offer and to answer.
+    Capabilities []MediaDescription
+    // IceUfrag and IcePwd are generated by the first CreateOffer or
+    // CreateAnswer when empty.
+    IceUfrag string
+    IcePwd   string
+    // Fingerprint is the a=fingerprint value, e.g. "sha-256 4A:AD:...".
+    Fingerprint string
+}
+
+// JSEPSession generates offers and answers and tracks the signaling state of
+// one side of a WebRTC session following RFC 8829. Every m-section is
+// bundled, uses rtcp-mux when it carries RTP and is described with trickle
+// ICE, so candidates are not included. A JSEPSession is not safe for
+// concurrent use.
+type JSEPSession struct {
+    config JSEPConfig
+    sessionID string
+    version int
+    state SignalingState
+    transceivers []*Transceiver
+    currentLocal, currentRemote *SessionDescription
+    pendingLocal, pendingRemote *SessionDescription
+    // proposed maps the mids chosen by CreateOffer to their transceivers.
+    proposed map[string]*Transceiver
+    // saved holds the transceivers and their mids before the pending offer,
+    // for rollback.
+    saved []*Transceiver
+    savedMids []string
+    // created holds the transceivers created by the pending remote offer.
+    created []*Transceiver
+    // role is the negotiated a=setup of this side.
+    role string
+}
+
+// NewJSEPSession returns a session in the stable state with no transceivers.
+func NewJSEPSession(c JSEPConfig) *JSEPSession {
+    return &JSEPSession{config: c}
+}
+
+// identify generates the session id and any ICE credentials missing from
+// the configuration, once.
+func (s *JSEPSession) identify() error {
+    var err error
+    if s.config.IceUfrag == "" {
+        if s.config.IceUfrag, err = randomString(12); err != nil {
+            return err
+        }
+    }
+    if s.config.IcePwd == "" {
+        if s.config.IcePwd, err = randomString(18); err != nil {
+            return err
+        }
+    }
+    if s.sessionID == "" {
+        var b [8]byte
+        if _, err := rand.Read(b[:]); err != nil {
+            return err
+        }
+        s.sessionID = strconv.FormatUint(binary.BigEndian.Uint64(b[:]) &^ (1 << 63), 10)
+    }
+    return nil
+}
+
+// randomString returns n random bytes encoded with ice-char characters.
+func randomString(n int) (string, error) {
+    b := make([]byte, n)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return base64.RawStdEncoding.EncodeToString(b), nil
+}
+
+// SignalingState returns the current signaling state.
+func (s *JSEPSession) SignalingState() SignalingState {
+    return s.state
+}
+
+// Transceivers returns the transceivers of s in creation order.
+func (s *JSEPSession) Transceivers() []*Transceiver {
+    return append([]*Transceiver(nil), s.transceivers...)
+}
+
+// LocalDescription returns the pending local description, or the current
+// one when there is none.
+func (s *JSEPSession) LocalDescription() *SessionDescription {
+    if s.pendingLocal != nil {
+        return s.pendingLocal
+    }
+    return s.currentLocal
+}
+
+// RemoteDescription returns the pending remote description, or the current
+// one when there is none.
+func (s *JSEPSession) RemoteDescription() *SessionDescription {
+    if s.pendingRemote != nil {
+        return s.pendingRemote
+    }
+    return s.currentRemote
+}
+
+// AddTransceiver adds a transceiver of the given kind, which must match the
+// type of one of the configured capabilities.
+func (s *JSEPSession) AddTransceiver(kind, direction string) (*Transceiver, error) {
+    if !contains(directions, direction) {
+        return nil, errors.New(badDirection)
+    }
+    c := s.capability(kind)
+    if c == nil {
+        return nil, errors.New(noCapability)
+    }
+    t := &Transceiver{Kind: kind, Direction: direction, capability: c}
+    s.transceivers = append(s.transceivers, t)
+    return t, nil
+}
+
+func (s *JSEPSession) capability(kind string) *MediaDescription {
+    for i := range s.config.Capabilities {
+        if s.config.Capabilities[i].Type == kind {
+            return &s.config.Capabilities[i]
+        }
+    }
+    return nil
+}
+
+func (s *JSEPSession) transceiver(mid string) *Transceiver {
+    if mid == "" {
+        return nil
+    }
+    for _, t := range s.transceivers {
+        if t.Mid == mid {
+            return t
+        }
+    }
+    return nil
+}
+
+// CreateOffer returns an offer for the current transceivers. m-sections of
+// the current local description keep their position; those whose
+// transceiver is gone are recycled for new transceivers, which are given
+// fresh mids.
+func (s *JSEPSession) CreateOffer() (*SessionDescription, error) {
+    if s.state != Stable && s.state != HaveLocalOffer {
+        return nil, errors.New(badSignalingState)
+    }
+    if err := s.identify(); err != nil {
+        return nil, err
+    }
+    used := map[string]bool{}
+    for _, t := range s.transceivers {
+        used[t.Mid] = true
+    }
+    var sections []MediaDescription
+    var recyclable []int
+    if s.currentLocal != nil {
+        for i := range s.currentLocal.MediaDescriptions {
+            m := &s.currentLocal.MediaDescriptions[i]
+            mid := m.Mid()
+            used[mid] = true
+            if t := s.transceiver(mid); t != nil {
+                sections = append(sections, s.offerMedia(t, mid))
+            } else {
+                sections = append(sections, rejectMedia(m))
+                recyclable = append(recyclable, i)
+            }
+        }
+    }
+    s.proposed = map[string]*Transceiver{}
+    next := 0
+    for _, t := range s.transceivers {
+        mid := t.Mid
+        switch {
+        case t.Stopped && mid == "":
+            continue
+        case mid != "":
+            // Associated by the pending local offer.
+            if s.currentLocal != nil && mediaIndex(s.currentLocal, mid) >= 0 {
+                continue
+            }
+        default:
+            for used[strconv.Itoa(next)] {
+                next++
+            }
+            mid = strconv.Itoa(next)
+            used[mid] = true
+            s.proposed[mid] = t
+        }
+        m := s.offerMedia(t, mid)
+        if len(recyclable) > 0 {
+            sections[recyclable[0]] = m
+            recyclable = recyclable[1:]
+        } else {
+            sections = append(sections, m)
+        }
+    }
+    offer := s.newDescription(sections)
+    s.setVersion(offer)
+    return offer, nil
+}
+
+// offerMedia returns the m-section offering t under mid.
+func (s *JSEPSession) offerMedia(t *Transceiver, mid string) MediaDescription {
+    c := t.capability
+    m := MediaDescription{
+        Type: c.Type,
+        Port: 9,
+        Proto: c.Proto,
+        Formats: append([]string(nil), c.Formats...),
+        Attributes: []Attribute{Attribute{"mid", mid}},
+    }
+    if t.Stopped {
+        return rejectMedia(&m)
+    }
+    setup := s.role
+    if setup == "" {
+        setup = "actpass"
+    }
+    s.transportAttributes(&m, setup)
+    if isRTP(m.Proto) {
+        m.Attributes = append(m.Attributes, Attribute{t.Direction, ""}, Attribute{"rtcp-mux", ""}, Attribute{"rtcp-rsize", ""})
+    }
+    m.Attributes = append(m.Attributes, filterAttributes(c.Attributes, func(a Attribute) bool {
+        return a.Key != "mid" && a.Key != "setup" && !contains(directions, a.Key)
+    })...)
+    return m
+}
+
+// transportAttributes adds the connection data, ICE and DTLS attributes
+// every accepted m-section carries.
+func (s *JSEPSession) transportAttributes(m *MediaDescription, setup string) {
+    m.Connections = []Connection{Connection{"IN", "IP4", "0.0.0.0"}}
+    m.Attributes = append(m.Attributes,
+        Attribute{"ice-ufrag", s.config.IceUfrag},
+        Attribute{"ice-pwd", s.config.IcePwd},
+        Attribute{"ice-options", "trickle"})
+    if s.config.Fingerprint != "" {
+        m.Attributes = append(m.Attributes, Attribute{"fingerprint", s.config.Fingerprint})
+    }
+    m.Attributes = append(m.Attributes, Attribute{"setup", setup})
+}
+
+// CreateAnswer answers the pending remote offer. Offered m-sections with no
+// live transceiver or nothing in common with its capability are rejected,
+// and the accepted ones that were offered bundled are bundled.
+func (s *JSEPSession) CreateAnswer() (*SessionDescription, error) {
+    if s.state != HaveRemoteOffer && s.state != HaveLocalPranswer {
+        return nil, errors.New(badSignalingState)
+    }
+    if err := s.identify(); err != nil {
+        return nil, err
+    }
+    offer := s.pendingRemote
+    var sections []MediaDescription
+    for i := range offer.MediaDescriptions {
+        om := &offer.MediaDescriptions[i]
+        sections = append(sections, s.answerMedia(offer, om, s.transceiver(om.Mid())))
+    }
+    answer := s.newDescription(nil)
+    answer.MediaDescriptions = sections
+    var bundle []string
+    for _, mid := range bundleGroup(offer) {
+        for i := range sections {
+            if sections[i].Port != 0 && sections[i].Mid() == mid {
+                bundle = append(bundle, mid)
+            }
+        }
+    }
+    if len(bundle) > 0 {
+        answer.Attributes = append(answer.Attributes, Attribute{"group", "BUNDLE " + strings.Join(bundle, " ")})
+    }
+    s.setVersion(answer)
+    return answer, nil
+}
+
+func (s *JSEPSession) answerMedia(offer *SessionDescription, om *MediaDescription, t *Transceiver) MediaDescription {
+    if t == nil || t.Stopped || om.Port == 0 {
+        return rejectMedia(om)
+    }
+    lm := t.capability
+    formats, attrs := negotiateMedia(om, lm)
+    if len(formats) == 0 {
+        return rejectMedia(om)
+    }
+    am := MediaDescription{
+        Type: om.Type,
+        Port: 9,
+        Proto: om.Proto,
+        Formats: formats,
+        Attributes: []Attribute{Attribute{"mid", t.Mid}},
+    }
+    setup := "active"
+    if v, _ := offer.MediaAttribute(om, "setup"); v == "active" {
+        setup = "passive"
+    }
+    s.transportAttributes(&am, setup)
+    if isRTP(am.Proto) {
+        am.Attributes = append(am.Attributes, Attribute{answerDirection(offer.Direction(om), t.Direction), ""})
+        if _, ok := offer.MediaAttribute(om, "rtcp-mux"); ok {
+            am.Attributes = append(am.Attributes, Attribute{"rtcp-mux", ""})
+        }
+    }
+    am.Attributes = append(am.Attributes, attrs...)
+    am.Attributes = append(am.Attributes, filterAttributes(localAttributes(lm), func(a Attribute) bool { return a.Key != "setup" })...)
+    return am
+}
+
+// newDescription returns the session level part of a local description
+// with the given m-sections, all accepted ones being bundled.
+func (s *JSEPSession) newDescription(sections []MediaDescription) *SessionDescription {
+    sd := NewSessionDescription()
+    sd.Origin = Origin{"-", s.sessionID, strconv.Itoa(s.version), "IN", "IP4", "0.0.0.0"}
+    sd.SessionName = "-"
+    sd.Times = []TimeDescription{TimeDescription{}}
+    var bundle []string
+    for i := range sections {
+        if sections[i].Port != 0 {
+            bundle = append(bundle, sections[i].Mid())
+        }
+    }
+    if len(bundle) > 0 {
+        sd.Attributes = append(sd.Attributes, Attribute{"group", "BUNDLE " + strings.Join(bundle, " ")})
+    }
+    sd.MediaDescriptions = sections
+    return sd
+}
+
+// setVersion bumps the origin version of sd when it differs from the last
+// local description.
+func (s *JSEPSession) setVersion(sd *SessionDescription) {
+    if last := s.LocalDescription(); last != nil && !Equal(last, sd, EqualOptions{IgnoreOriginVersion: true}) {
+        s.version++
+        sd.Origin.SessionVersion = strconv.Itoa(s.version)
+    }
+}
+
+// bundleGroup returns the mids of the first BUNDLE group of sd.
+func bundleGroup(sd *SessionDescription) []string {
+    for _, a := range sd.Attributes {
+        if tokens := strings.Fields(a.Value); a.Key == "group" && len(tokens) > 0 && tokens[0] == "BUNDLE" {
+            return tokens[1:]
+        }
+    }
+    return nil
+}
+
+// SetLocalDescription applies a description created by CreateOffer or
+// CreateAnswer, possibly modified, or rolls back the pending local offer.
+func (s *JSEPSession) SetLocalDescription(typ DescriptionType, sd *SessionDescription) error {
+    if sd == nil && typ != RollbackType {
+        return errors.New(noDescription)
+    }
+    switch {
+    case typ == RollbackType && s.state == HaveLocalOffer:
+        s.rollback()
+    case typ == OfferType && (s.state == Stable || s.state == HaveLocalOffer):
+        if s.state == Stable {
+            s.save()
+        }
+        for i := range sd.MediaDescriptions {
+            mid := sd.MediaDescriptions[i].Mid()
+            if t := s.proposed[mid]; t != nil && t.Mid == "" {
+                t.Mid = mid
+            }
+        }
+        s.pendingLocal = sd.Clone()
+        s.state = HaveLocalOffer
+    case typ == PranswerType && (s.state == HaveRemoteOffer || s.state == HaveLocalPranswer):
+        s.pendingLocal = sd.Clone()
+        s.state = HaveLocalPranswer
+    case typ == AnswerType && (s.state == HaveRemoteOffer || s.state == HaveLocalPranswer):
+        if len(sd.MediaDescriptions) != len(s.pendingRemote.MediaDescriptions) {
+            return errors.New(badAnswer)
+        }
+        s.complete(sd.Clone(), s.pendingRemote, false)
+    default:
+        return errors.New(badSignalingState)
+    }
+    return nil
+}
+
+// SetRemoteDescription applies a description received from the peer, or
+// rolls back the pending remote offer. Offered m-sections are associated
+// with the transceiver of the same mid, else with an unassociated
+// transceiver of the same kind, else with a new recvonly transceiver when
+// the kind is supported.
+func (s *JSEPSession) SetRemoteDescription(typ DescriptionType, sd *SessionDescription) error {
+    if sd == nil && typ != RollbackType {
+        return errors.New(noDescription)
+    }
+    switch {
+    case typ == RollbackType && s.state == HaveRemoteOffer:
+        s.rollback()
+    case typ == OfferType && (s.state == Stable || s.state == HaveRemoteOffer):
+        for i := range sd.MediaDescriptions {
+            if sd.MediaDescriptions[i].Mid() == "" {
+                return errors.New(noMid)
+            }
+        }
+        if s.state == HaveRemoteOffer {
+            s.rollback()
+        }
+        s.save()
+        for i := range sd.MediaDescriptions {
+            s.associate(&sd.MediaDescriptions[i])
+        }
+        s.pendingRemote = sd.Clone()
+        s.state = HaveRemoteOffer
+    case typ == PranswerType && (s.state == HaveLocalOffer || s.state == HaveRemotePranswer):
+        if len(sd.MediaDescriptions) != len(s.pendingLocal.MediaDescriptions) {
+            return errors.New(badAnswer)
+        }
+        s.pendingRemote = sd.Clone()
+        s.state = HaveRemotePranswer
+    case typ == AnswerType && (s.state == HaveLocalOffer || s.state == HaveRemotePranswer):
+        if len(sd.MediaDescriptions) != len(s.pendingLocal.MediaDescriptions) {
+            return errors.New(badAnswer)
+        }
+        s.complete(s.pendingLocal, sd.Clone(), true)
+    default:
+        return errors.New(badSignalingState)
+    }
+    return nil
+}
+
+// associate finds or creates the transceiver for the offered m-section m.
+func (s *JSEPSession) associate(m *MediaDescription) {
+    mid := m.Mid()
+    if s.transceiver(mid) != nil || m.Port == 0 {
+        return
+    }
+    for _, t := range s.transceivers {
+        if t.Mid == "" && !t.Stopped && t.Kind == m.Type {
+            t.Mid = mid
+            return
+        }
+    }
+    if c := s.capability(m.Type); c != nil {
+        t := &Transceiver{Mid: mid, Kind: m.Type, Direction: "recvonly", capability: c}
+        s.transceivers = append(s.transceivers, t)
+        s.created = append(s.created, t)
+    }
+}
+
+// save records the transceivers before an offer is applied.
+func (s *JSEPSession) save() {
+    s.saved = append([]*Transceiver(nil), s.transceivers...)
+    s.savedMids, s.created = nil, nil
+    for _, t := range s.saved {
+        s.savedMids = append(s.savedMids, t.Mid)
+    }
+}
+
+// rollback discards the pending offer and restores the transceivers
+// recorded by save. Transceivers created by a remote offer are dropped and
+// those added locally since are kept unassociated.
+func (s *JSEPSession) rollback() {
+    transceivers := s.saved
+    for _, t := range s.transceivers {
+        if !containsTransceiver(s.saved, t) && !containsTransceiver(s.created, t) {
+            t.Mid = ""
+            transceivers = append(transceivers, t)
+        }
+    }
+    for i, t := range s.saved {
+        t.Mid = s.savedMids[i]
+    }
+    s.transceivers = transceivers
+    s.pendingLocal, s.pendingRemote = nil, nil
+    s.proposed, s.saved, s.savedMids, s.created = nil, nil, nil, nil
+    s.state = Stable
+}
+
+func containsTransceiver(ts []*Transceiver, t *Transceiver) bool {
+    for _, u := range ts {
+        if u == t {
+            return true
+        }
+    }
+    return false
+}
+
+// complete makes local and remote the current descriptions, records the
+// negotiated directions and DTLS role, and drops the transceivers whose
+// m-section was rejected, freeing the m-section for recycling.
+func (s *JSEPSession) complete(local, remote *SessionDescription, offerer bool) {
+    s.currentLocal, s.currentRemote = local, remote
+    s.pendingLocal, s.pendingRemote = nil, nil
+    s.proposed, s.saved, s.savedMids, s.created = nil, nil, nil, nil
+    s.state = Stable
+    var live []*Transceiver
+    for _, t := range s.transceivers {
+        i := mediaIndex(local, t.Mid)
+        if t.Mid == "" || i < 0 {
+            if !t.Stopped {
+                live = append(live, t)
+            }
+            continue
+        }
+        lm, rm := &local.MediaDescriptions[i], &remote.MediaDescriptions[i]
+        if lm.Port == 0 || rm.Port == 0 {
+            t.Mid, t.CurrentDirection, t.Stopped = "", "", true
+            continue
+        }
+        live = append(live, t)
+        if offerer {
+            t.CurrentDirection = reverseDirection(remote.Direction(rm))
+            if setup, _ := remote.MediaAttribute(rm, "setup"); setup == "active" {
+                s.role = "passive"
+            } else if setup == "passive" {
+                s.role = "active"
+            }
+        } else {
+            t.CurrentDirection = local.Direction(lm)
+            if setup, _ := local.MediaAttribute(lm, "setup"); setup != "" {
+                s.role = setup
+            }
+        }
+    }
+    s.transceivers = live
+}
+
+// mediaIndex returns the index of the m-section of sd with the given mid,
+// or -1.
+func mediaIndex(sd *SessionDescription, mid string) int {
+    for i := range sd.MediaDescriptions {
+        if sd.MediaDescriptions[i].Mid() == mid {
+            return i
+        }
+    }
+    return -1
+}
+
+// reverseDirection returns direction d as seen from the other side.
+func reverseDirection(d string) string {
+    switch d {
+    case "sendonly":
+        return "recvonly"
+    case "recvonly":
+        return "sendonly"
+    }
+    return d
+}
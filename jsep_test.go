@@ -0,0 +1,162 @@
+package sdp
+
+import (
+    "strings"
+    "testing"
+    )
+
+var (
+    opus = MediaDescription{Type: "audio", Proto: "UDP/TLS/RTP/SAVPF", Formats: []string{"111"},
+        Attributes: []Attribute{{"rtpmap", "111 opus/48000/2"}, {"fmtp", "111 minptime=10;useinbandfec=1"}}}
+    vp8 = MediaDescription{Type: "video", Proto: "UDP/TLS/RTP/SAVPF", Formats: []string{"96"},
+        Attributes: []Attribute{{"rtpmap", "96 VP8/90000"}}}
+    datachannel = MediaDescription{Type: "application", Proto: "UDP/DTLS/SCTP", Formats: []string{"webrtc-datachannel"},
+        Attributes: []Attribute{{"sctp-port", "5000"}, {"max-message-size", "262144"}}}
+    )
+
+// sections summarizes the m-sections of sd as "mid:port:direction".
+func sections(sd *SessionDescription) string {
+    var s []string
+    for i := range sd.MediaDescriptions {
+        m := &sd.MediaDescriptions[i]
+        d := ""
+        if isRTP(m.Proto) && m.Port != 0 {
+            d = ":" + sd.Direction(m)
+        }
+        s = append(s, m.Type + ":" + m.Mid() + ":" + portString(m) + d)
+    }
+    return strings.Join(s, " ")
+}
+
+func TestJSEP(t *testing.T) {
+    alice := NewJSEPSession(JSEPConfig{Capabilities: []MediaDescription{opus, vp8, datachannel}, Fingerprint: "sha-256 AA:BB"})
+    bob := NewJSEPSession(JSEPConfig{Capabilities: []MediaDescription{opus, datachannel}, Fingerprint: "sha-256 CC:DD"})
+    audio, _ := alice.AddTransceiver("audio", "sendrecv")
+    video, _ := alice.AddTransceiver("video", "sendonly")
+    alice.AddTransceiver("application", "sendrecv")
+    if _, err := alice.AddTransceiver("text", "sendrecv"); err == nil {
+        t.Error("expected error for unsupported kind")
+    }
+    offer, err := alice.CreateOffer()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, want := sections(offer), "audio:0:9:sendrecv video:1:9:sendonly application:2:9"; got != want {
+        t.Errorf("offer: got %q, want %q", got, want)
+    }
+    if v, _ := offer.Attribute("group"); v != "BUNDLE 0 1 2" {
+        t.Errorf("offer group: %q", v)
+    }
+    if v, _ := offer.MediaAttribute(&offer.MediaDescriptions[0], "setup"); v != "actpass" {
+        t.Errorf("offer setup: %q", v)
+    }
+    if _, ok := offer.MediaDescriptions[0].Attribute("rtcp-mux"); !ok {
+        t.Error("offer lacks rtcp-mux")
+    }
+    if err := alice.SetRemoteDescription(AnswerType, offer); err == nil {
+        t.Error("expected error setting an answer in stable state")
+    }
+    if err := alice.SetLocalDescription(OfferType, offer); err != nil {
+        t.Fatal(err)
+    }
+    if alice.SignalingState() != HaveLocalOffer || audio.Mid != "0" {
+        t.Fatalf("after local offer: %v, mid %q", alice.SignalingState(), audio.Mid)
+    }
+
+    // Bob supports no video: the video section is rejected and unbundled.
+    text, err := offer.Encode()
+    if err != nil {
+        t.Fatal(err)
+    }
+    received, err := Decode(string(text))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := bob.SetRemoteDescription(OfferType, received); err != nil {
+        t.Fatal(err)
+    }
+    answer, err := bob.CreateAnswer()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, want := sections(answer), "audio:0:9:recvonly video:1:0 application:2:9"; got != want {
+        t.Errorf("answer: got %q, want %q", got, want)
+    }
+    if v, _ := answer.Attribute("group"); v != "BUNDLE 0 2" {
+        t.Errorf("answer group: %q", v)
+    }
+    if err := bob.SetLocalDescription(AnswerType, answer); err != nil {
+        t.Fatal(err)
+    }
+    if err := alice.SetRemoteDescription(AnswerType, answer); err != nil {
+        t.Fatal(err)
+    }
+    if alice.SignalingState() != Stable || bob.SignalingState() != Stable {
+        t.Fatalf("not stable: %v %v", alice.SignalingState(), bob.SignalingState())
+    }
+    if audio.CurrentDirection != "sendonly" || !video.Stopped || video.Mid != "" {
+        t.Errorf("after answer: audio %q, video stopped %v mid %q", audio.CurrentDirection, video.Stopped, video.Mid)
+    }
+    if n := len(alice.Transceivers()); n != 2 {
+        t.Errorf("got %d transceivers, want 2", n)
+    }
+    if got := bob.Transceivers()[0].CurrentDirection; got != "recvonly" {
+        t.Errorf("bob audio direction %q", got)
+    }
+
+    // A new video transceiver recycles the rejected section with a new mid.
+    alice.AddTransceiver("video", "sendrecv")
+    reoffer, err := alice.CreateOffer()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, want := sections(reoffer), "audio:0:9:sendrecv video:3:9:sendrecv application:2:9"; got != want {
+        t.Errorf("re-offer: got %q, want %q", got, want)
+    }
+    if reoffer.Origin.SessionVersion != "1" || reoffer.Origin.SessionId != offer.Origin.SessionId {
+        t.Errorf("re-offer origin: %v", reoffer.Origin)
+    }
+    if v, _ := reoffer.MediaAttribute(&reoffer.MediaDescriptions[0], "setup"); v != "passive" {
+        t.Errorf("re-offer setup: %q", v)
+    }
+
+    // Rolling back the re-offer leaves the new transceiver unassociated.
+    if err := alice.SetLocalDescription(OfferType, reoffer); err != nil {
+        t.Fatal(err)
+    }
+    if err := alice.SetLocalDescription(RollbackType, nil); err != nil {
+        t.Fatal(err)
+    }
+    ts := alice.Transceivers()
+    if alice.SignalingState() != Stable || len(ts) != 3 || ts[2].Mid != "" || alice.LocalDescription() != alice.currentLocal {
+        t.Errorf("after rollback: %v, %d transceivers", alice.SignalingState(), len(ts))
+    }
+
+    // Rolling back a remote offer drops the transceivers it created.
+    carol := NewJSEPSession(JSEPConfig{Capabilities: []MediaDescription{opus}})
+    if err := carol.SetRemoteDescription(OfferType, received); err != nil {
+        t.Fatal(err)
+    }
+    if n := len(carol.Transceivers()); n != 1 {
+        t.Errorf("got %d transceivers, want 1", n)
+    }
+    if err := carol.SetRemoteDescription(RollbackType, nil); err != nil {
+        t.Fatal(err)
+    }
+    if n := len(carol.Transceivers()); n != 0 || carol.SignalingState() != Stable {
+        t.Errorf("after rollback: %d transceivers, %v", n, carol.SignalingState())
+    }
+}
+
+func TestJSEPNoDescription(t *testing.T) {
+    s := NewJSEPSession(JSEPConfig{Capabilities: []MediaDescription{opus}})
+    if err := s.SetRemoteDescription(OfferType, nil); err == nil {
+        t.Error("nil remote offer accepted")
+    }
+    if err := s.SetLocalDescription(AnswerType, nil); err == nil {
+        t.Error("nil local answer accepted")
+    }
+    if s.SignalingState() != Stable {
+        t.Errorf("state: %v", s.SignalingState())
+    }
+}
@@ -0,0 +1,540 @@
+package sdp
+
+import (
+    "encoding/json"
+    "errors"
+    "strconv"
+    "strings"
+    "time"
+    )
+
+// JSON schema
+//
+// A SessionDescription marshals to an object with the keys version, origin,
+// sessionName, info, uri, emails, phones, connection, bandwidths, times, key,
+// attributes and media. Optional keys are omitted when empty.
+//
+//   origin        {"username", "sessionId", "sessionVersion", "netType", "addrType", "unicastAddr"}
+//   email, phone  {"address", "name"}
+//   connection    {"netType", "addrType", "address"}
+//   bandwidth     {"type", "bandwidth"}
+//   key           {"method", "key"}
+//   time          {"start", "stop", "repeats", "zones"}
+//   repeat        {"interval", "active", "offsets"}
+//   zone          {"time", "offset"}
+//   media         {"type", "port", "numPorts", "proto", "formats", "info",
+//                  "connections", "bandwidths", "key", "attributes"}
+//
+// Times are written as seconds since the NTP epoch, 0 standing for the zero
+// time; RFC 3339 strings are accepted when reading. Durations are whole
+// seconds; durations with a fraction of a second are an error.
+//
+// Attributes are the raw "name:value" string, as it appears after a= in
+// SDP, except for three typed attributes written as objects:
+//
+//   rtpmap     {"name", "payloadType", "encoding", "clockRate", "channels"}
+//   fmtp       {"name", "payloadType", "parameters": [{"name", "value"}]}
+//   candidate  {"name", "foundation", "component", "transport", "priority",
+//               "address", "port", "type", "relatedAddress", "relatedPort",
+//               "extensions": [{"name", "value"}]}
+//
+// A typed attribute whose value these fields cannot reproduce exactly, such
+// as an rtpmap without a clock rate, is written as a raw string. Objects
+// {"name", "value"} are accepted for any attribute when reading.
+
+const (
+    badJSON string = "bad JSON value"
+    )
+
+type jsonSessionDescription struct {
+    Version           int                `json:"version"`
+    Origin            Origin             `json:"origin"`
+    SessionName       string             `json:"sessionName"`
+    Info              string             `json:"info,omitempty"`
+    Uri               string             `json:"uri,omitempty"`
+    Emails            []Email            `json:"emails,omitempty"`
+    Phones            []Phone            `json:"phones,omitempty"`
+    Connection        *Connection        `json:"connection,omitempty"`
+    Bandwidths        []Bandwidth        `json:"bandwidths,omitempty"`
+    Times             []TimeDescription  `json:"times,omitempty"`
+    Key               *Key               `json:"key,omitempty"`
+    Attributes        []Attribute        `json:"attributes,omitempty"`
+    MediaDescriptions []MediaDescription `json:"media,omitempty"`
+}
+
+func (sd SessionDescription) MarshalJSON() ([]byte, error) {
+    j := jsonSessionDescription{
+        Version: sd.Version,
+        Origin: sd.Origin,
+        SessionName: sd.SessionName,
+        Info: sd.Info,
+        Uri: sd.Uri,
+        Emails: sd.Emails,
+        Phones: sd.Phones,
+        Bandwidths: sd.Bandwidths,
+        Times: sd.Times,
+        Attributes: sd.Attributes,
+        MediaDescriptions: sd.MediaDescriptions,
+    }
+    if sd.Connection != (Connection{}) {
+        j.Connection = &sd.Connection
+    }
+    if sd.Key != (Key{}) {
+        j.Key = &sd.Key
+    }
+    return json.Marshal(j)
+}
+
+func (sd *SessionDescription) UnmarshalJSON(b []byte) error {
+    var j jsonSessionDescription
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *sd = SessionDescription{
+        Version: j.Version,
+        Origin: j.Origin,
+        SessionName: j.SessionName,
+        Info: j.Info,
+        Uri: j.Uri,
+        Emails: j.Emails,
+        Phones: j.Phones,
+        Bandwidths: j.Bandwidths,
+        Times: j.Times,
+        Attributes: j.Attributes,
+        MediaDescriptions: j.MediaDescriptions,
+    }
+    if j.Connection != nil {
+        sd.Connection = *j.Connection
+    }
+    if j.Key != nil {
+        sd.Key = *j.Key
+    }
+    return nil
+}
+
+// MarshalText encodes sd as SDP with the default options, so that text
+// based formats such as YAML carry the description as a single string.
+func (sd SessionDescription) MarshalText() ([]byte, error) {
+    return sd.encode(EncodeOptions{})
+}
+
+// UnmarshalText decodes SDP text into sd.
+func (sd *SessionDescription) UnmarshalText(b []byte) error {
+    d, err := Decode(string(b))
+    if err != nil {
+        return err
+    }
+    *sd = *d
+    return nil
+}
+
+type jsonOrigin struct {
+    Username       string `json:"username"`
+    SessionId      string `json:"sessionId"`
+    SessionVersion string `json:"sessionVersion"`
+    NetType        string `json:"netType"`
+    AddrType       string `json:"addrType"`
+    UnicastAddr    string `json:"unicastAddr"`
+}
+
+func (o Origin) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonOrigin(o))
+}
+
+func (o *Origin) UnmarshalJSON(b []byte) error {
+    var j jsonOrigin
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *o = Origin(j)
+    return nil
+}
+
+type jsonContact struct {
+    Address string `json:"address"`
+    Name    string `json:"name,omitempty"`
+}
+
+func (e Email) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonContact(e))
+}
+
+func (e *Email) UnmarshalJSON(b []byte) error {
+    var j jsonContact
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *e = Email(j)
+    return nil
+}
+
+func (p Phone) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonContact(p))
+}
+
+func (p *Phone) UnmarshalJSON(b []byte) error {
+    var j jsonContact
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *p = Phone(j)
+    return nil
+}
+
+type jsonConnection struct {
+    NetType  string `json:"netType"`
+    AddrType string `json:"addrType"`
+    Address  string `json:"address"`
+}
+
+func (c Connection) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonConnection(c))
+}
+
+func (c *Connection) UnmarshalJSON(b []byte) error {
+    var j jsonConnection
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *c = Connection(j)
+    return nil
+}
+
+type jsonBandwidth struct {
+    Type      string `json:"type"`
+    Bandwidth string `json:"bandwidth"`
+}
+
+func (bw Bandwidth) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonBandwidth(bw))
+}
+
+func (bw *Bandwidth) UnmarshalJSON(b []byte) error {
+    var j jsonBandwidth
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *bw = Bandwidth(j)
+    return nil
+}
+
+type jsonKey struct {
+    Method string `json:"method"`
+    Key    string `json:"key,omitempty"`
+}
+
+func (k Key) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonKey(k))
+}
+
+func (k *Key) UnmarshalJSON(b []byte) error {
+    var j jsonKey
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *k = Key(j)
+    return nil
+}
+
+// jsonTime is a time written as seconds since the NTP epoch.
+type jsonTime time.Time
+
+func (t jsonTime) MarshalJSON() ([]byte, error) {
+    return json.Marshal(ntpTime(time.Time(t)))
+}
+
+func (t *jsonTime) UnmarshalJSON(b []byte) error {
+    var secs int64
+    if err := json.Unmarshal(b, &secs); err == nil {
+        *t = jsonTime(fromNTP(secs))
+        return nil
+    }
+    var s string
+    if err := json.Unmarshal(b, &s); err != nil {
+        return errors.New(badJSON)
+    }
+    v, err := time.Parse(time.RFC3339, s)
+    if err != nil {
+        return err
+    }
+    *t = jsonTime(v)
+    return nil
+}
+
+// jsonDuration is a duration written as whole seconds.
+type jsonDuration time.Duration
+
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+    if time.Duration(d) % time.Second != 0 {
+        return nil, errors.New(badDuration)
+    }
+    return json.Marshal(int64(time.Duration(d) / time.Second))
+}
+
+func (d *jsonDuration) UnmarshalJSON(b []byte) error {
+    var secs int64
+    if err := json.Unmarshal(b, &secs); err != nil {
+        return err
+    }
+    *d = jsonDuration(time.Duration(secs) * time.Second)
+    return nil
+}
+
+type jsonTimeDescription struct {
+    Start   jsonTime `json:"start"`
+    Stop    jsonTime `json:"stop"`
+    Repeats []Repeat `json:"repeats,omitempty"`
+    Zones   []Zone   `json:"zones,omitempty"`
+}
+
+func (t TimeDescription) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonTimeDescription{jsonTime(t.Start), jsonTime(t.Stop), t.Repeats, t.Zones})
+}
+
+func (t *TimeDescription) UnmarshalJSON(b []byte) error {
+    var j jsonTimeDescription
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *t = TimeDescription{time.Time(j.Start), time.Time(j.Stop), j.Repeats, j.Zones}
+    return nil
+}
+
+type jsonRepeat struct {
+    Interval jsonDuration   `json:"interval"`
+    Active   jsonDuration   `json:"active"`
+    Offsets  []jsonDuration `json:"offsets,omitempty"`
+}
+
+func (r Repeat) MarshalJSON() ([]byte, error) {
+    j := jsonRepeat{Interval: jsonDuration(r.Interval), Active: jsonDuration(r.Active)}
+    for _, o := range r.Offsets {
+        j.Offsets = append(j.Offsets, jsonDuration(o))
+    }
+    return json.Marshal(j)
+}
+
+func (r *Repeat) UnmarshalJSON(b []byte) error {
+    var j jsonRepeat
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *r = Repeat{Interval: time.Duration(j.Interval), Active: time.Duration(j.Active)}
+    for _, o := range j.Offsets {
+        r.Offsets = append(r.Offsets, time.Duration(o))
+    }
+    return nil
+}
+
+type jsonZone struct {
+    Time   jsonTime     `json:"time"`
+    Offset jsonDuration `json:"offset"`
+}
+
+func (z Zone) MarshalJSON() ([]byte, error) {
+    return json.Marshal(jsonZone{jsonTime(z.Time), jsonDuration(z.Offset)})
+}
+
+func (z *Zone) UnmarshalJSON(b []byte) error {
+    var j jsonZone
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *z = Zone{time.Time(j.Time), time.Duration(j.Offset)}
+    return nil
+}
+
+type jsonAttribute struct {
+    Name  string  `json:"name"`
+    Value *string `json:"value,omitempty"`
+}
+
+type jsonRTPMap struct {
+    Name        string `json:"name"`
+    PayloadType int    `json:"payloadType"`
+    Encoding    string `json:"encoding"`
+    ClockRate   int    `json:"clockRate"`
+    Channels    int    `json:"channels,omitempty"`
+}
+
+type jsonFmtp struct {
+    Name        string          `json:"name"`
+    PayloadType int             `json:"payloadType"`
+    Parameters  []jsonParameter `json:"parameters"`
+}
+
+type jsonParameter struct {
+    Name  string `json:"name"`
+    Value string `json:"value,omitempty"`
+}
+
+type jsonCandidate struct {
+    Name           string          `json:"name"`
+    Foundation     string          `json:"foundation"`
+    Component      int             `json:"component"`
+    Transport      string          `json:"transport"`
+    Priority       uint32          `json:"priority"`
+    Address        string          `json:"address"`
+    Port           int             `json:"port"`
+    Type           string          `json:"type"`
+    RelatedAddress string          `json:"relatedAddress,omitempty"`
+    RelatedPort    int             `json:"relatedPort,omitempty"`
+    Extensions     []jsonParameter `json:"extensions,omitempty"`
+}
+
+func (a Attribute) MarshalJSON() ([]byte, error) {
+    if v, ok := typedAttribute(a); ok {
+        return json.Marshal(v)
+    }
+    return json.Marshal(a.String())
+}
+
+// typedAttribute returns the object form of a typed attribute, or false if
+// a is not typed or its value does not round trip through the object.
+func typedAttribute(a Attribute) (interface{}, bool) {
+    var v interface{}
+    switch a.Key {
+    case "rtpmap":
+        r, err := parseRTPMap(a.Value)
+        if err != nil {
+            return nil, false
+        }
+        v = jsonRTPMap{a.Key, r.PayloadType, r.EncodingName, r.ClockRate, r.Channels}
+    case "fmtp":
+        pt, params, _ := strings.Cut(a.Value, " ")
+        j := jsonFmtp{Name: a.Key, Parameters: []jsonParameter{}}
+        var err error
+        if j.PayloadType, err = strconv.Atoi(pt); err != nil {
+            return nil, false
+        }
+        for _, p := range strings.Split(params, ";") {
+            k, v, _ := strings.Cut(p, "=")
+            j.Parameters = append(j.Parameters, jsonParameter{k, v})
+        }
+        v = j
+    case "candidate":
+        c, err := ParseICECandidate(a.Value)
+        if err != nil {
+            return nil, false
+        }
+        j := jsonCandidate{a.Key, c.Foundation, c.Component, c.Transport, c.Priority, c.Address, c.Port, c.Type, c.RelatedAddress, c.RelatedPort, nil}
+        for _, e := range c.Extensions {
+            j.Extensions = append(j.Extensions, jsonParameter{e.Key, e.Value})
+        }
+        v = j
+    default:
+        return nil, false
+    }
+    b, err := json.Marshal(v)
+    if err != nil {
+        return nil, false
+    }
+    var rt Attribute
+    if err := rt.UnmarshalJSON(b); err != nil || rt != a {
+        return nil, false
+    }
+    return v, true
+}
+
+func (a *Attribute) UnmarshalJSON(b []byte) error {
+    var s string
+    if err := json.Unmarshal(b, &s); err == nil {
+        return a.UnmarshalText([]byte(s))
+    }
+    var j jsonAttribute
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    if j.Value != nil {
+        *a = Attribute{j.Name, *j.Value}
+        return nil
+    }
+    switch j.Name {
+    case "rtpmap":
+        var r jsonRTPMap
+        if err := json.Unmarshal(b, &r); err != nil {
+            return err
+        }
+        *a = Attribute{r.Name, RTPMap{r.PayloadType, r.Encoding, r.ClockRate, r.Channels}.String()}
+    case "fmtp":
+        var f jsonFmtp
+        if err := json.Unmarshal(b, &f); err != nil {
+            return err
+        }
+        var params []string
+        for _, p := range f.Parameters {
+            if p.Value != "" {
+                params = append(params, p.Name + "=" + p.Value)
+            } else {
+                params = append(params, p.Name)
+            }
+        }
+        *a = Attribute{f.Name, strconv.Itoa(f.PayloadType) + " " + strings.Join(params, ";")}
+    case "candidate":
+        var c jsonCandidate
+        if err := json.Unmarshal(b, &c); err != nil {
+            return err
+        }
+        ic := ICECandidate{c.Foundation, c.Component, c.Transport, c.Priority, c.Address, c.Port, c.Type, c.RelatedAddress, c.RelatedPort, nil}
+        for _, e := range c.Extensions {
+            ic.Extensions = append(ic.Extensions, Attribute{e.Name, e.Value})
+        }
+        *a = Attribute{c.Name, ic.String()}
+    default:
+        *a = Attribute{j.Name, ""}
+    }
+    return nil
+}
+
+type jsonMediaDescription struct {
+    Type        string       `json:"type"`
+    Port        int          `json:"port"`
+    NumPorts    int          `json:"numPorts,omitempty"`
+    Proto       string       `json:"proto"`
+    Formats     []string     `json:"formats"`
+    Info        string       `json:"info,omitempty"`
+    Connections []Connection `json:"connections,omitempty"`
+    Bandwidths  []Bandwidth  `json:"bandwidths,omitempty"`
+    Key         *Key         `json:"key,omitempty"`
+    Attributes  []Attribute  `json:"attributes,omitempty"`
+}
+
+func (m MediaDescription) MarshalJSON() ([]byte, error) {
+    j := jsonMediaDescription{
+        Type: m.Type,
+        Port: m.Port,
+        NumPorts: m.NumPorts,
+        Proto: m.Proto,
+        Formats: m.Formats,
+        Info: m.Info,
+        Connections: m.Connections,
+        Bandwidths: m.Bandwidths,
+        Attributes: m.Attributes,
+    }
+    if m.Key != (Key{}) {
+        j.Key = &m.Key
+    }
+    return json.Marshal(j)
+}
+
+func (m *MediaDescription) UnmarshalJSON(b []byte) error {
+    var j jsonMediaDescription
+    if err := json.Unmarshal(b, &j); err != nil {
+        return err
+    }
+    *m = MediaDescription{
+        Type: j.Type,
+        Port: j.Port,
+        NumPorts: j.NumPorts,
+        Proto: j.Proto,
+        Formats: j.Formats,
+        Info: j.Info,
+        Connections: j.Connections,
+        Bandwidths: j.Bandwidths,
+        Attributes: j.Attributes,
+    }
+    if j.Key != nil {
+        m.Key = *j.Key
+    }
+    return nil
+}
@@ -0,0 +1,84 @@
+package sdp
+
+import (
+    "encoding/json"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+    )
+
+func TestJSONRoundTrip(t *testing.T) {
+    in, err := Decode(s1 + "a=mid:1\na=x-custom:1 2\na=fmtp:99 profile=0;level=10;x\na=rtpmap:98\n" +
+        "a=candidate:1 1 udp 2130706431 192.0.2.1 5000 typ srflx raddr 10.0.0.1 rport 5000 generation 0\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+    b, err := json.Marshal(in)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{
+        `"start":2873397496`,
+        `"repeats":[{"interval":604800,"active":3600,"offsets":[0,90000]}`,
+        `{"time":2882844526,"offset":-3600}`,
+        `"attributes":[{"name":"rtpmap","payloadType":99,"encoding":"h263-1998","clockRate":90000},"mid:1","x-custom:1 2",` +
+            `{"name":"fmtp","payloadType":99,"parameters":[{"name":"profile","value":"0"},{"name":"level","value":"10"},{"name":"x"}]},"rtpmap:98",` +
+            `{"name":"candidate","foundation":"1","component":1,"transport":"udp","priority":2130706431,"address":"192.0.2.1","port":5000,"type":"srflx",` +
+            `"relatedAddress":"10.0.0.1","relatedPort":5000,"extensions":[{"name":"generation","value":"0"}]}]`,
+        `"connection":{"netType":"IN","addrType":"IP4","address":"224.2.17.12/127"}`,
+    } {
+        if !strings.Contains(string(b), want) {
+            t.Errorf("missing %s in %s", want, b)
+        }
+    }
+    out := new(SessionDescription)
+    if err := json.Unmarshal(b, out); err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(in, out) {
+        t.Errorf("round trip mismatch:\n%+v\n%+v", in, out)
+    }
+}
+
+func TestJSONAttributes(t *testing.T) {
+    AttrTypes = append(AttrTypes, "x-custom")
+    defer func() { AttrTypes = AttrTypes[:len(AttrTypes)-1] }()
+    for _, a := range []Attribute{Attribute{"x-custom", "1"}, Attribute{"fmtp", "99 a=1;b="}, Attribute{"fmtp", "99"}, Attribute{"candidate", "1 1 udp 1 192.0.2.1 5000 typ host raddr 10.0.0.1"}} {
+        b, err := json.Marshal(a)
+        if err != nil || string(b) != `"` + a.String() + `"` {
+            t.Errorf("%v: got %s, %v", a, b, err)
+        }
+    }
+    var a Attribute
+    if err := json.Unmarshal([]byte(`{"name":"mid","value":"1"}`), &a); err != nil || a != (Attribute{"mid", "1"}) {
+        t.Errorf("name/value object: %v, %v", a, err)
+    }
+    if _, err := json.Marshal(Repeat{Interval: time.Hour, Active: 1500 * time.Millisecond}); err == nil {
+        t.Errorf("expected error for a sub-second duration")
+    }
+}
+
+func TestJSONTimes(t *testing.T) {
+    var td TimeDescription
+    if err := json.Unmarshal([]byte(`{"start":"2014-01-02T03:04:05Z","stop":0}`), &td); err != nil {
+        t.Fatal(err)
+    }
+    if !td.Start.Equal(time.Date(2014, 1, 2, 3, 4, 5, 0, time.UTC)) || !td.Stop.IsZero() {
+        t.Errorf("wrong times: %v", td)
+    }
+}
+
+func TestTextRoundTrip(t *testing.T) {
+    var sd SessionDescription
+    if err := sd.UnmarshalText([]byte(s1)); err != nil {
+        t.Fatal(err)
+    }
+    b, err := sd.MarshalText()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.HasPrefix(string(b), "v=0\r\no=jdoe ") {
+        t.Errorf("wrong text: %s", b)
+    }
+}
@@ -0,0 +1,224 @@
+package sdp
+
+import (
+    "errors"
+    "net"
+    "net/url"
+    "strconv"
+    "strings"
+    )
+
+const (
+    notMSRP string = "not an MSRP protocol"
+    badMSRPURI string = "bad MSRP URI"
+    )
+
+// MSRPURI is an msrp: or msrps: URI of a=path (RFC 4975), e.g.
+// msrp://atlanta.example.com:7654/jshA7weztas;tcp.
+type MSRPURI struct {
+    Secure    bool // msrps scheme
+    Host      string
+    Port      int // 0 when absent
+    SessionID string
+    Transport string
+}
+
+// ParseMSRPURI parses an msrp: or msrps: URI.
+func ParseMSRPURI(s string) (MSRPURI, error) {
+    u, err := url.Parse(s)
+    if err != nil {
+        return MSRPURI{}, err
+    }
+    var m MSRPURI
+    switch strings.ToLower(u.Scheme) {
+    case "msrp":
+    case "msrps":
+        m.Secure = true
+    default:
+        return MSRPURI{}, errors.New(badMSRPURI)
+    }
+    m.Host = u.Hostname()
+    if p := u.Port(); p != "" {
+        if m.Port, err = parsePort(p); err != nil {
+            return MSRPURI{}, err
+        }
+    }
+    tokens := strings.SplitN(strings.TrimPrefix(u.Path, "/"), ";", 2)
+    if m.Host == "" || len(tokens) != 2 || tokens[0] == "" || tokens[1] == "" {
+        return MSRPURI{}, errors.New(badMSRPURI)
+    }
+    m.SessionID, m.Transport = tokens[0], tokens[1]
+    return m, nil
+}
+
+func (m MSRPURI) String() string {
+    s := "msrp://"
+    if m.Secure {
+        s = "msrps://"
+    }
+    switch {
+    case m.Port > 0:
+        s += net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+    case strings.Contains(m.Host, ":"):
+        s += "[" + m.Host + "]"
+    default:
+        s += m.Host
+    }
+    return s + "/" + m.SessionID + ";" + m.Transport
+}
+
+// FileSelector is the value of a=file-selector (RFC 5547). Fields are empty
+// or 0 when absent.
+type FileSelector struct {
+    Name string
+    Type string
+    Size int64
+    Hash string // e.g. sha-1:72:24:5F:...
+}
+
+func parseFileSelector(s string) (FileSelector, error) {
+    var f FileSelector
+    for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+        colon := strings.Index(s, ":")
+        if colon < 1 {
+            return FileSelector{}, errors.New(badGrammar)
+        }
+        key := s[:colon]
+        s = s[colon+1:]
+        var value string
+        if key == "name" && strings.HasPrefix(s, `"`) {
+            end := strings.Index(s[1:], `"`)
+            if end < 0 {
+                return FileSelector{}, errors.New(badGrammar)
+            }
+            value, s = s[1:end+1], s[end+2:]
+        } else {
+            end := strings.IndexAny(s, " \t")
+            if end < 0 {
+                end = len(s)
+            }
+            value, s = s[:end], s[end:]
+        }
+        switch key {
+        case "name":
+            f.Name = value
+        case "type":
+            f.Type = value
+        case "size":
+            n, err := strconv.ParseInt(value, 10, 64)
+            if err != nil {
+                return FileSelector{}, err
+            }
+            f.Size = n
+        case "hash":
+            f.Hash = value
+        }
+    }
+    return f, nil
+}
+
+func (f FileSelector) String() string {
+    var s []string
+    if f.Name != "" {
+        s = append(s, `name:"` + f.Name + `"`)
+    }
+    if f.Type != "" {
+        s = append(s, "type:" + f.Type)
+    }
+    if f.Size > 0 {
+        s = append(s, "size:" + strconv.FormatInt(f.Size, 10))
+    }
+    if f.Hash != "" {
+        s = append(s, "hash:" + f.Hash)
+    }
+    return strings.Join(s, " ")
+}
+
+// MSRPParams are the MSRP attributes of a message media description. MaxSize
+// is 0 and FileSelector nil when absent.
+type MSRPParams struct {
+    Path               []MSRPURI
+    AcceptTypes        []string
+    AcceptWrappedTypes []string
+    MaxSize            int64
+    FileSelector       *FileSelector
+}
+
+// isMSRP reports whether proto carries MSRP.
+func isMSRP(proto string) bool {
+    m := MediaDescription{Proto: proto}
+    return m.Protocol().MSRP
+}
+
+// MSRP returns the MSRP parameters of m.
+func (m *MediaDescription) MSRP() (MSRPParams, error) {
+    var p MSRPParams
+    if !isMSRP(m.Proto) {
+        return p, errors.New(notMSRP)
+    }
+    if v, ok := m.Attribute("path"); ok {
+        for _, s := range strings.Fields(v) {
+            u, err := ParseMSRPURI(s)
+            if err != nil {
+                return MSRPParams{}, err
+            }
+            p.Path = append(p.Path, u)
+        }
+    }
+    if v, ok := m.Attribute("accept-types"); ok {
+        p.AcceptTypes = strings.Fields(v)
+    }
+    if v, ok := m.Attribute("accept-wrapped-types"); ok {
+        p.AcceptWrappedTypes = strings.Fields(v)
+    }
+    if v, ok := m.Attribute("max-size"); ok {
+        n, err := strconv.ParseInt(v, 10, 64)
+        if err != nil {
+            return MSRPParams{}, err
+        }
+        p.MaxSize = n
+    }
+    if v, ok := m.Attribute("file-selector"); ok {
+        f, err := parseFileSelector(v)
+        if err != nil {
+            return MSRPParams{}, err
+        }
+        p.FileSelector = &f
+    }
+    return p, nil
+}
+
+// SetMSRP replaces the MSRP attributes of m with p, omitting empty ones.
+func (m *MediaDescription) SetMSRP(p MSRPParams) error {
+    if !isMSRP(m.Proto) {
+        return errors.New(notMSRP)
+    }
+    attrs := filterAttributes(m.Attributes, func(a Attribute) bool {
+        switch a.Key {
+        case "path", "accept-types", "accept-wrapped-types", "max-size", "file-selector":
+            return false
+        }
+        return true
+    })
+    if len(p.AcceptTypes) > 0 {
+        attrs = append(attrs, Attribute{"accept-types", strings.Join(p.AcceptTypes, " ")})
+    }
+    if len(p.AcceptWrappedTypes) > 0 {
+        attrs = append(attrs, Attribute{"accept-wrapped-types", strings.Join(p.AcceptWrappedTypes, " ")})
+    }
+    if p.MaxSize > 0 {
+        attrs = append(attrs, Attribute{"max-size", strconv.FormatInt(p.MaxSize, 10)})
+    }
+    if p.FileSelector != nil {
+        attrs = append(attrs, Attribute{"file-selector", p.FileSelector.String()})
+    }
+    if len(p.Path) > 0 {
+        var path []string
+        for _, u := range p.Path {
+            path = append(path, u.String())
+        }
+        attrs = append(attrs, Attribute{"path", strings.Join(path, " ")})
+    }
+    m.Attributes = attrs
+    return nil
+}
@@ -0,0 +1,56 @@
+package sdp
+
+import (
+    "reflect"
+    "testing"
+    )
+
+var msrpOffer = `v=0
+o=alice 2890844526 2890844527 IN IP4 alice.example.com
+s=-
+c=IN IP4 alice.example.com
+t=0 0
+m=message 7654 TCP/MSRP *
+a=accept-types:message/cpim text/plain
+a=accept-wrapped-types:*
+a=max-size:1048576
+a=file-selector:name:"My cool picture.jpg" type:image/jpeg size:32349 hash:sha-1:72:24:5F:E8:65:3D:DA:F3:71:36:2F:86:D4:71:91:3E:E4:A2:CE:2E
+a=path:msrp://alice.example.com:7654/jshA7weztas;tcp msrps://[2001:db8::1]/9di4ea;tcp
+`
+
+func TestMSRP(t *testing.T) {
+    sd, err := Decode(msrpOffer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    m := &sd.MediaDescriptions[0]
+    p, err := m.MSRP()
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := MSRPParams{
+        Path: []MSRPURI{
+            {false, "alice.example.com", 7654, "jshA7weztas", "tcp"},
+            {true, "2001:db8::1", 0, "9di4ea", "tcp"},
+        },
+        AcceptTypes: []string{"message/cpim", "text/plain"},
+        AcceptWrappedTypes: []string{"*"},
+        MaxSize: 1048576,
+        FileSelector: &FileSelector{"My cool picture.jpg", "image/jpeg", 32349, "sha-1:72:24:5F:E8:65:3D:DA:F3:71:36:2F:86:D4:71:91:3E:E4:A2:CE:2E"},
+    }
+    if !reflect.DeepEqual(p, want) {
+        t.Errorf("got %+v, want %+v", p, want)
+    }
+    c := m.Clone()
+    if err := c.SetMSRP(p); err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(c.Attributes, m.Attributes) {
+        t.Errorf("round trip:\ngot  %v\nwant %v", c.Attributes, m.Attributes)
+    }
+    for _, s := range []string{"sip:alice@example.com", "msrp://host:7654/;tcp", "msrp://host/abc"} {
+        if _, err := ParseMSRPURI(s); err == nil {
+            t.Errorf("%s: expected error", s)
+        }
+    }
+}
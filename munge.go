@@ -0,0 +1,138 @@
+package sdp
+
+import (
+    "strings"
+    )
+
+// codecs describes every format of m, keyed by format. Formats without an
+// rtpmap or static payload type have only their PayloadType set.
+func (m *MediaDescription) codecs() (map[string]RTPCodecParameters, error) {
+    pts, err := m.PayloadTypes()
+    if err != nil {
+        return nil, err
+    }
+    codecs := map[string]RTPCodecParameters{}
+    for i, pt := range pts {
+        codecs[m.Formats[i]], _ = m.codecParameters(m.Formats[i], pt)
+    }
+    return codecs, nil
+}
+
+// encodingName returns the encoding name part of the MimeType of c.
+func encodingName(c RTPCodecParameters) string {
+    _, name, _ := strings.Cut(c.MimeType, "/")
+    return name
+}
+
+// codecMatches reports whether c is called name, either an encoding name
+// such as H264 or a MimeType such as video/H264, ignoring case.
+func codecMatches(c RTPCodecParameters, name string) bool {
+    return c.MimeType != "" && (strings.EqualFold(c.MimeType, name) || strings.EqualFold(encodingName(c), name))
+}
+
+// dependencies returns the formats format f of m only makes sense with: the
+// apt= target of RTX and similar payloads, and the redundant encodings of
+// audio RED (RFC 2198).
+func (m *MediaDescription) dependencies(f string, c RTPCodecParameters) []string {
+    if apt := c.Parameters["apt"]; apt != "" {
+        return []string{apt}
+    }
+    if strings.EqualFold(encodingName(c), "red") {
+        if fmtp, ok := m.Fmtp(f); ok && !strings.Contains(fmtp, "=") {
+            return strings.Split(strings.TrimSpace(fmtp), "/")
+        }
+    }
+    return nil
+}
+
+// KeepCodecs removes the codecs of m for which keep returns false, along
+// with the RTX, FEC and RED payloads depending on a removed codec, and
+// drops their rtpmap, fmtp and rtcp-fb attributes. keep is called for
+// dependent payloads too, so RemoveCodec("rtx") strips every RTX payload.
+// When no codec is left Formats is empty and the caller should reject or
+// remove the section.
+func (m *MediaDescription) KeepCodecs(keep func(RTPCodecParameters) bool) error {
+    codecs, err := m.codecs()
+    if err != nil {
+        return err
+    }
+    kept := map[string]bool{}
+    visiting := map[string]bool{}
+    var decide func(f string) bool
+    decide = func(f string) bool {
+        if ok, done := kept[f]; done {
+            return ok
+        }
+        c, known := codecs[f]
+        if !known || visiting[f] {
+            return false
+        }
+        visiting[f] = true
+        ok := keep(c)
+        for _, d := range m.dependencies(f, c) {
+            ok = ok && decide(d)
+        }
+        kept[f] = ok
+        return ok
+    }
+    var formats []string
+    for _, f := range m.Formats {
+        if decide(f) {
+            formats = append(formats, f)
+        }
+    }
+    m.Formats = formats
+    m.Attributes = filterAttributes(m.Attributes, func(a Attribute) bool {
+        switch a.Key {
+        case "rtpmap", "fmtp", "rtcp-fb":
+            pt, _, _ := strings.Cut(a.Value, " ")
+            _, isFormat := codecs[pt]
+            return !isFormat || kept[pt]
+        }
+        return true
+    })
+    return nil
+}
+
+// RemoveCodec removes the codecs of m called name, an encoding name such
+// as H264 or a MimeType such as video/H264, and the payloads depending on
+// them.
+func (m *MediaDescription) RemoveCodec(name string) error {
+    return m.KeepCodecs(func(c RTPCodecParameters) bool { return !codecMatches(c, name) })
+}
+
+// PreferCodecs moves the codecs called by names to the front of the m= line,
+// in the order of names, each followed by its RTX payloads. Other formats
+// keep their relative order.
+func (m *MediaDescription) PreferCodecs(names ...string) error {
+    codecs, err := m.codecs()
+    if err != nil {
+        return err
+    }
+    var formats []string
+    placed := map[string]bool{}
+    place := func(f string) {
+        formats = append(formats, f)
+        placed[f] = true
+    }
+    for _, name := range names {
+        for _, f := range m.Formats {
+            if placed[f] || !codecMatches(codecs[f], name) {
+                continue
+            }
+            place(f)
+            for _, g := range m.Formats {
+                if !placed[g] && codecs[g].Parameters["apt"] == f {
+                    place(g)
+                }
+            }
+        }
+    }
+    for _, f := range m.Formats {
+        if !placed[f] {
+            place(f)
+        }
+    }
+    m.Formats = formats
+    return nil
+}
@@ -0,0 +1,64 @@
+package sdp
+
+import (
+    "os"
+    "strings"
+    "testing"
+    )
+
+func TestMunge(t *testing.T) {
+    b, err := os.ReadFile("testdata/webrtc-chrome-offer.sdp")
+    if err != nil {
+        t.Fatal(err)
+    }
+    sd, err := Decode(string(b))
+    if err != nil {
+        t.Fatal(err)
+    }
+    video := sd.MediaDescriptions[1].Clone()
+    if err := video.RemoveCodec("H264"); err != nil {
+        t.Fatal(err)
+    }
+    if got := strings.Join(video.Formats, " "); got != "96 97 45 46" {
+        t.Errorf("formats: %s", got)
+    }
+    for _, a := range video.Attributes {
+        if strings.HasPrefix(a.Value, "102 ") || strings.HasPrefix(a.Value, "103 ") {
+            t.Errorf("attribute left: %s", a)
+        }
+    }
+    if err := video.PreferCodecs("AV1"); err != nil {
+        t.Fatal(err)
+    }
+    if got := strings.Join(video.Formats, " "); got != "45 46 96 97" {
+        t.Errorf("preferred formats: %s", got)
+    }
+    if err := video.RemoveCodec("rtx"); err != nil {
+        t.Fatal(err)
+    }
+    if got := strings.Join(video.Formats, " "); got != "45 96" {
+        t.Errorf("formats without rtx: %s", got)
+    }
+
+    // Removing opus takes the RED payload built on it along.
+    audio := sd.MediaDescriptions[0].Clone()
+    if err := audio.RemoveCodec("audio/opus"); err != nil {
+        t.Fatal(err)
+    }
+    if got := strings.Join(audio.Formats, " "); got != "9 0 8 13 110 126" {
+        t.Errorf("audio formats: %s", got)
+    }
+    if err := audio.KeepCodecs(func(c RTPCodecParameters) bool { return c.ClockRate == 8000 && c.MimeType != "audio/CN" }); err != nil {
+        t.Fatal(err)
+    }
+    if got := strings.Join(audio.Formats, " "); got != "9 0 8 126" {
+        t.Errorf("8 kHz formats: %s", got)
+    }
+    if len(audio.AttributeValues("rtpmap")) != 4 || len(audio.AttributeValues("fmtp")) != 0 {
+        t.Errorf("audio attributes: %v", audio.Attributes)
+    }
+    app := &sd.MediaDescriptions[2]
+    if err := app.RemoveCodec("opus"); err == nil {
+        t.Error("expected error for a data channel section")
+    }
+}
@@ -0,0 +1,369 @@
+package sdp
+
+import (
+    "errors"
+    "slices"
+    "strconv"
+    "strings"
+    )
+
+const (
+    noSSRCAttribute string = "SSRC without a CNAME or MSID to describe it"
+    )
+
+// RTCPFeedback is an a=rtcp-fb entry of a codec, e.g. nack pli.
+type RTCPFeedback struct {
+    Type      string
+    Parameter string
+}
+
+// RTPCodecParameters describes a codec of an RTP m-section. MimeType is the
+// media type and encoding name, e.g. audio/opus, and Parameters the a=fmtp
+// parameters.
+type RTPCodecParameters struct {
+    MimeType     string
+    PayloadType  int
+    ClockRate    int
+    Channels     int
+    Parameters   map[string]string
+    RTCPFeedback []RTCPFeedback
+}
+
+// RTPHeaderExtensionParameters is an a=extmap entry (RFC 8285).
+type RTPHeaderExtensionParameters struct {
+    URI       string
+    ID        int
+    Direction string // empty when not given
+    Attributes string
+}
+
+// RTPEncodingParameters is a stream sent in an m-section, identified by its
+// SSRC, its RID or both. RTX is the SSRC of its retransmission stream.
+type RTPEncodingParameters struct {
+    SSRC uint32
+    RID  string
+    RTX  uint32
+}
+
+// RTCPParameters describes the RTCP of an m-section.
+type RTCPParameters struct {
+    CNAME       string
+    ReducedSize bool
+    Mux         bool
+}
+
+// RTPParameters are the RTP parameters of an m-section, in the shape media
+// engines in the ORTC tradition consume.
+type RTPParameters struct {
+    Mid              string
+    // MSID is the a=msid of the m-section, or the msid of its SSRCs.
+    MSID             string
+    Codecs           []RTPCodecParameters
+    HeaderExtensions []RTPHeaderExtensionParameters
+    Encodings        []RTPEncodingParameters
+    RTCP             RTCPParameters
+}
+
+// DTLSFingerprint is an a=fingerprint value.
+type DTLSFingerprint struct {
+    Algorithm string
+    Value     string
+}
+
+// TransportParameters are the ICE and DTLS parameters of an m-section. Role
+// is its a=setup value.
+type TransportParameters struct {
+    IceUfrag     string
+    IcePwd       string
+    IceLite      bool
+    IceOptions   []string
+    Candidates   []ICECandidate
+    Fingerprints []DTLSFingerprint
+    Role         string
+}
+
+// RTPParameters returns the codecs, header extensions, encodings and RTCP
+// settings of m. Codecs follow the m= line order; static payload types
+// without a=rtpmap are described from DefaultCodecRegistry. Encodings come
+// from a=rid when present and from a=ssrc otherwise, an FID group making
+// its second SSRC the RTX stream of the first.
+func (m *MediaDescription) RTPParameters() (RTPParameters, error) {
+    pts, err := m.PayloadTypes()
+    if err != nil {
+        return RTPParameters{}, err
+    }
+    p := RTPParameters{Mid: m.Mid()}
+    p.MSID, _ = m.Attribute("msid")
+    feedback := map[string][]RTCPFeedback{}
+    for _, v := range m.AttributeValues("rtcp-fb") {
+        tokens := strings.Fields(v)
+        if len(tokens) < 2 {
+            return RTPParameters{}, errors.New(badGrammar)
+        }
+        feedback[tokens[0]] = append(feedback[tokens[0]], RTCPFeedback{tokens[1], strings.Join(tokens[2:], " ")})
+    }
+    for i, pt := range pts {
+        c, ok := m.codecParameters(m.Formats[i], pt)
+        if !ok {
+            continue
+        }
+        c.RTCPFeedback = append(append(c.RTCPFeedback, feedback["*"]...), feedback[m.Formats[i]]...)
+        p.Codecs = append(p.Codecs, c)
+    }
+    for _, v := range m.AttributeValues("extmap") {
+        tokens := strings.SplitN(v, " ", 3)
+        if len(tokens) < 2 {
+            return RTPParameters{}, errors.New(badGrammar)
+        }
+        id, direction, _ := strings.Cut(tokens[0], "/")
+        e := RTPHeaderExtensionParameters{URI: tokens[1], Direction: direction}
+        if e.ID, err = strconv.Atoi(id); err != nil {
+            return RTPParameters{}, err
+        }
+        if len(tokens) == 3 {
+            e.Attributes = tokens[2]
+        }
+        p.HeaderExtensions = append(p.HeaderExtensions, e)
+    }
+    rtx := map[uint32]uint32{}
+    for _, v := range m.AttributeValues("ssrc-group") {
+        tokens := strings.Fields(v)
+        if len(tokens) == 3 && tokens[0] == "FID" {
+            primary, err1 := parseSSRC(tokens[1])
+            secondary, err2 := parseSSRC(tokens[2])
+            if err1 != nil || err2 != nil {
+                return RTPParameters{}, errors.New(badGrammar)
+            }
+            rtx[primary] = secondary
+        }
+    }
+    isRTX := map[uint32]bool{}
+    for _, s := range rtx {
+        isRTX[s] = true
+    }
+    seen := map[uint32]bool{}
+    for _, v := range m.AttributeValues("ssrc") {
+        id, attr, _ := strings.Cut(v, " ")
+        ssrc, err := parseSSRC(id)
+        if err != nil {
+            return RTPParameters{}, err
+        }
+        if cname, ok := strings.CutPrefix(attr, "cname:"); ok && p.RTCP.CNAME == "" {
+            p.RTCP.CNAME = cname
+        }
+        if msid, ok := strings.CutPrefix(attr, "msid:"); ok && p.MSID == "" {
+            p.MSID = msid
+        }
+        if !seen[ssrc] && !isRTX[ssrc] {
+            p.Encodings = append(p.Encodings, RTPEncodingParameters{SSRC: ssrc, RTX: rtx[ssrc]})
+        }
+        seen[ssrc] = true
+    }
+    if rids := m.AttributeValues("rid"); len(rids) > 0 {
+        p.Encodings = nil
+        for _, v := range rids {
+            tokens := strings.Fields(v)
+            if len(tokens) < 2 {
+                return RTPParameters{}, errors.New(badGrammar)
+            }
+            p.Encodings = append(p.Encodings, RTPEncodingParameters{RID: tokens[0]})
+        }
+    }
+    _, p.RTCP.Mux = m.Attribute("rtcp-mux")
+    _, p.RTCP.ReducedSize = m.Attribute("rtcp-rsize")
+    return p, nil
+}
+
+// codecParameters describes format f, payload type pt, of m from its
+// rtpmap, or DefaultCodecRegistry for static payload types, and its fmtp.
+func (m *MediaDescription) codecParameters(f string, pt int) (RTPCodecParameters, bool) {
+    r, ok := m.RTPMap(f)
+    if !ok {
+        info, static := DefaultCodecRegistry.ByPayloadType(pt)
+        if !static {
+            return RTPCodecParameters{PayloadType: pt}, false
+        }
+        r = info.RTPMap(pt)
+    }
+    c := RTPCodecParameters{
+        MimeType: m.Type + "/" + r.EncodingName,
+        PayloadType: pt,
+        ClockRate: r.ClockRate,
+        Channels: r.Channels,
+        Parameters: map[string]string{},
+    }
+    if fmtp, ok := m.Fmtp(f); ok {
+        c.Parameters = parseFmtpParams(fmtp)
+    }
+    return c, true
+}
+
+func parseSSRC(s string) (uint32, error) {
+    n, err := strconv.ParseUint(s, 10, 32)
+    return uint32(n), err
+}
+
+// TransportParameters returns the ICE and DTLS parameters of m, taking
+// session level attributes when m has none of its own.
+func (sd *SessionDescription) TransportParameters(m *MediaDescription) (TransportParameters, error) {
+    var t TransportParameters
+    t.IceUfrag, _ = sd.MediaAttribute(m, "ice-ufrag")
+    t.IcePwd, _ = sd.MediaAttribute(m, "ice-pwd")
+    _, t.IceLite = sd.Attribute("ice-lite")
+    if v, ok := sd.MediaAttribute(m, "ice-options"); ok {
+        t.IceOptions = strings.Fields(v)
+    }
+    t.Role, _ = sd.MediaAttribute(m, "setup")
+    for _, v := range m.AttributeValues("candidate") {
+        c, err := ParseICECandidate(v)
+        if err != nil {
+            return TransportParameters{}, err
+        }
+        t.Candidates = append(t.Candidates, c)
+    }
+    fingerprints := m.AttributeValues("fingerprint")
+    if len(fingerprints) == 0 {
+        for _, a := range sd.Attributes {
+            if a.Key == "fingerprint" {
+                fingerprints = append(fingerprints, a.Value)
+            }
+        }
+    }
+    for _, v := range fingerprints {
+        algorithm, value, ok := strings.Cut(v, " ")
+        if !ok {
+            return TransportParameters{}, errors.New(badGrammar)
+        }
+        t.Fingerprints = append(t.Fingerprints, DTLSFingerprint{algorithm, strings.TrimSpace(value)})
+    }
+    return t, nil
+}
+
+// FromRTPParameters builds an m-section of the given kind and protocol from
+// RTP and transport parameters. The port and connection address are those
+// of the first RTP component candidate, or 9 and 0.0.0.0 without one. RID
+// encodings are announced as sent with a=simulcast. Each SSRC gets a=ssrc
+// lines with the CNAME and MSID; it fails for SSRCs when both are empty.
+func FromRTPParameters(kind, proto string, p RTPParameters, t TransportParameters) (MediaDescription, error) {
+    m := MediaDescription{Type: kind, Port: 9, Proto: proto}
+    address := "0.0.0.0"
+    for _, c := range t.Candidates {
+        if c.Component == 1 {
+            m.Port, address = c.Port, c.Address
+            break
+        }
+    }
+    addrType := "IP4"
+    if strings.Contains(address, ":") {
+        addrType = "IP6"
+    }
+    m.Connections = []Connection{Connection{"IN", addrType, address}}
+    add := func(key, value string) {
+        m.Attributes = append(m.Attributes, Attribute{key, value})
+    }
+    if p.Mid != "" {
+        add("mid", p.Mid)
+    }
+    if p.MSID != "" {
+        add("msid", p.MSID)
+    }
+    if t.IceUfrag != "" {
+        add("ice-ufrag", t.IceUfrag)
+        add("ice-pwd", t.IcePwd)
+    }
+    if len(t.IceOptions) > 0 {
+        add("ice-options", strings.Join(t.IceOptions, " "))
+    }
+    for _, f := range t.Fingerprints {
+        add("fingerprint", f.Algorithm + " " + f.Value)
+    }
+    if t.Role != "" {
+        add("setup", t.Role)
+    }
+    for _, c := range t.Candidates {
+        add("candidate", c.String())
+    }
+    for _, e := range p.HeaderExtensions {
+        id := strconv.Itoa(e.ID)
+        if e.Direction != "" {
+            id += "/" + e.Direction
+        }
+        v := id + " " + e.URI
+        if e.Attributes != "" {
+            v += " " + e.Attributes
+        }
+        add("extmap", v)
+    }
+    if p.RTCP.Mux {
+        add("rtcp-mux", "")
+    }
+    if p.RTCP.ReducedSize {
+        add("rtcp-rsize", "")
+    }
+    for _, c := range p.Codecs {
+        pt := strconv.Itoa(c.PayloadType)
+        m.Formats = append(m.Formats, pt)
+        _, name, _ := strings.Cut(c.MimeType, "/")
+        add("rtpmap", RTPMap{c.PayloadType, name, c.ClockRate, c.Channels}.String())
+        if len(c.Parameters) > 0 {
+            add("fmtp", pt + " " + formatParameters(c.Parameters))
+        }
+        for _, fb := range c.RTCPFeedback {
+            v := pt + " " + fb.Type
+            if fb.Parameter != "" {
+                v += " " + fb.Parameter
+            }
+            add("rtcp-fb", v)
+        }
+    }
+    var rids []string
+    for _, e := range p.Encodings {
+        if e.RID != "" {
+            add("rid", e.RID + " send")
+            rids = append(rids, e.RID)
+        }
+    }
+    if len(rids) > 0 {
+        add("simulcast", "send " + strings.Join(rids, ";"))
+    }
+    for _, e := range p.Encodings {
+        if e.SSRC == 0 {
+            continue
+        }
+        ssrc := strconv.FormatUint(uint64(e.SSRC), 10)
+        if e.RTX != 0 {
+            add("ssrc-group", "FID " + ssrc + " " + strconv.FormatUint(uint64(e.RTX), 10))
+        }
+        if p.RTCP.CNAME == "" && p.MSID == "" {
+            return MediaDescription{}, errors.New(noSSRCAttribute)
+        }
+        for _, s := range []uint32{e.SSRC, e.RTX} {
+            if s == 0 {
+                continue
+            }
+            id := strconv.FormatUint(uint64(s), 10)
+            if p.RTCP.CNAME != "" {
+                add("ssrc", id + " cname:" + p.RTCP.CNAME)
+            }
+            if p.MSID != "" {
+                add("ssrc", id + " msid:" + p.MSID)
+            }
+        }
+    }
+    return m, nil
+}
+
+// formatParameters returns fmtp parameters as k=v pairs joined by
+// semicolons, sorted by key so the output is stable.
+func formatParameters(params map[string]string) string {
+    var pairs []string
+    for k, v := range params {
+        if v == "" {
+            pairs = append(pairs, k)
+        } else {
+            pairs = append(pairs, k + "=" + v)
+        }
+    }
+    slices.Sort(pairs)
+    return strings.Join(pairs, ";")
+}
@@ -0,0 +1,92 @@
+package sdp
+
+import (
+    "os"
+    "reflect"
+    "testing"
+    )
+
+func TestRTPParameters(t *testing.T) {
+    b, err := os.ReadFile("testdata/webrtc-chrome-offer.sdp")
+    if err != nil {
+        t.Fatal(err)
+    }
+    sd, err := Decode(string(b))
+    if err != nil {
+        t.Fatal(err)
+    }
+    video := &sd.MediaDescriptions[1]
+    p, err := video.RTPParameters()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(p.Codecs) != 6 || p.Mid != "1" || len(p.HeaderExtensions) != 4 {
+        t.Fatalf("got %+v", p)
+    }
+    h264 := p.Codecs[2]
+    if h264.MimeType != "video/H264" || h264.ClockRate != 90000 || h264.Parameters["profile-level-id"] != "42001f" || len(h264.RTCPFeedback) != 2 {
+        t.Errorf("H264: %+v", h264)
+    }
+    if p.Codecs[0].RTCPFeedback[4] != (RTCPFeedback{"nack", "pli"}) {
+        t.Errorf("VP8 feedback: %v", p.Codecs[0].RTCPFeedback)
+    }
+    if len(p.Encodings) != 1 || p.Encodings[0] != (RTPEncodingParameters{SSRC: 2002, RTX: 2003}) {
+        t.Errorf("encodings: %v", p.Encodings)
+    }
+    if p.RTCP != (RTCPParameters{"Yq1L0oUyXpjsfYjL", true, true}) {
+        t.Errorf("RTCP: %+v", p.RTCP)
+    }
+    tp, err := sd.TransportParameters(video)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if tp.IceUfrag != "EsAw" || tp.Role != "actpass" || len(tp.Fingerprints) != 1 || tp.Fingerprints[0].Algorithm != "sha-256" {
+        t.Errorf("transport: %+v", tp)
+    }
+    c, err := ParseICECandidate("candidate:842163049 1 udp 1677729535 203.0.113.7 46154 typ srflx raddr 10.0.0.2 rport 46154 generation 0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tp.Candidates = []ICECandidate{c}
+
+    m, err := FromRTPParameters("video", video.Proto, p, tp)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if m.Port != 46154 || m.Connections[0].Address != "203.0.113.7" {
+        t.Errorf("address: %d %v", m.Port, m.Connections)
+    }
+    q, err := m.RTPParameters()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(p, q) {
+        t.Errorf("round trip:\ngot  %+v\nwant %+v", q, p)
+    }
+    sd.MediaDescriptions = []MediaDescription{m}
+    tq, err := sd.TransportParameters(&m)
+    if err != nil || !reflect.DeepEqual(tp, tq) {
+        t.Errorf("transport round trip:\ngot  %+v\nwant %+v", tq, tp)
+    }
+
+    // Without a CNAME the SSRCs are described by their msid alone.
+    p.RTCP.CNAME = ""
+    if m, err = FromRTPParameters("video", video.Proto, p, tp); err != nil {
+        t.Fatal(err)
+    }
+    if q, err = m.RTPParameters(); err != nil || !reflect.DeepEqual(p, q) {
+        t.Errorf("round trip without CNAME:\ngot  %+v\nwant %+v", q, p)
+    }
+    p.MSID = ""
+    if _, err := FromRTPParameters("video", video.Proto, p, tp); err == nil {
+        t.Errorf("expected error for SSRCs without CNAME or MSID")
+    }
+    if got := c.String(); got != "842163049 1 udp 1677729535 203.0.113.7 46154 typ srflx raddr 10.0.0.2 rport 46154 generation 0" {
+        t.Errorf("candidate: %q", got)
+    }
+    for _, s := range []string{"1 1 udp 1 host 9 typ", "1 x udp 1 10.0.0.1 9 typ host", "1 1 udp 1 10.0.0.1 9 host host"} {
+        if _, err := ParseICECandidate(s); err == nil {
+            t.Errorf("%q: expected error", s)
+        }
+    }
+}
@@ -0,0 +1,402 @@
+package sdp
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    )
+
+// PlanMappingEntry relates a Unified Plan m-section to the Plan B m-section
+// carrying its source, PlanBIndex being the m-line index of the latter.
+// MSID is the a=msid of the source, empty for sections without one.
+type PlanMappingEntry struct {
+    UnifiedMid string
+    PlanBMid   string
+    PlanBIndex int
+    MSID       string
+}
+
+// PlanMapping is the correspondence between the m-sections of a Unified
+// Plan description and its Plan B counterpart, in Unified Plan m-line
+// order. It is returned by ToUnifiedPlan and ToPlanB and used to translate
+// the answer coming back.
+type PlanMapping struct {
+    Entries []PlanMappingEntry
+}
+
+// PlanBMid returns the Plan B mid of the Unified Plan section mid.
+func (pm *PlanMapping) PlanBMid(mid string) (string, bool) {
+    for _, e := range pm.Entries {
+        if e.UnifiedMid == mid {
+            return e.PlanBMid, true
+        }
+    }
+    return "", false
+}
+
+// UnifiedMids returns the Unified Plan mids of the Plan B section mid.
+func (pm *PlanMapping) UnifiedMids(mid string) []string {
+    var mids []string
+    for _, e := range pm.Entries {
+        if e.PlanBMid == mid {
+            mids = append(mids, e.UnifiedMid)
+        }
+    }
+    return mids
+}
+
+// split reports whether the Plan B section at index i maps to several
+// Unified Plan sections.
+func (pm *PlanMapping) split(i int) bool {
+    n := 0
+    for _, e := range pm.Entries {
+        if e.PlanBIndex == i {
+            n++
+        }
+    }
+    return n > 1
+}
+
+// source is one media source of an m-section: the SSRCs sharing an msid or
+// an ssrc-group, with their a=ssrc and a=ssrc-group attributes.
+type source struct {
+    msid   string
+    ssrcs  []string
+    lines  map[string][]Attribute
+    groups []Attribute
+}
+
+// sources returns the sources of m in order of appearance. SSRCs are grouped
+// by their msid, from a=ssrc or the section's a=msid, and by ssrc-group.
+func sources(m *MediaDescription) []*source {
+    sectionMsid, _ := m.Attribute("msid")
+    key := map[string]string{}
+    msid := map[string]string{}
+    for _, v := range m.AttributeValues("ssrc") {
+        id, attr, _ := strings.Cut(v, " ")
+        if value, ok := strings.CutPrefix(attr, "msid:"); ok {
+            msid[id] = value
+            key[id] = "msid " + value
+        } else if sectionMsid != "" {
+            msid[id] = sectionMsid
+            key[id] = "msid " + sectionMsid
+        }
+    }
+    for _, v := range m.AttributeValues("ssrc-group") {
+        ids := strings.Fields(v)
+        if len(ids) < 2 {
+            continue
+        }
+        k := ""
+        for _, id := range ids[1:] {
+            if k = key[id]; k != "" {
+                break
+            }
+        }
+        if k == "" {
+            k = "ssrc " + ids[1]
+        }
+        for _, id := range ids[1:] {
+            if key[id] == "" {
+                key[id] = k
+            }
+        }
+    }
+    var srcs []*source
+    byKey := map[string]*source{}
+    get := func(id string) *source {
+        k := key[id]
+        if k == "" {
+            k = "ssrc " + id
+        }
+        src := byKey[k]
+        if src == nil {
+            src = &source{msid: msid[id], lines: map[string][]Attribute{}}
+            byKey[k] = src
+            srcs = append(srcs, src)
+        }
+        if src.msid == "" {
+            src.msid = msid[id]
+        }
+        return src
+    }
+    for _, a := range m.Attributes {
+        switch a.Key {
+        case "ssrc":
+            id, _, _ := strings.Cut(a.Value, " ")
+            src := get(id)
+            if _, ok := src.lines[id]; !ok {
+                src.ssrcs = append(src.ssrcs, id)
+            }
+            src.lines[id] = append(src.lines[id], a)
+        case "ssrc-group":
+            if ids := strings.Fields(a.Value); len(ids) > 1 {
+                src := get(ids[1])
+                src.groups = append(src.groups, a)
+            }
+        }
+    }
+    return srcs
+}
+
+// attributes returns the a=ssrc and a=ssrc-group lines of src, adding an
+// msid to each SSRC lacking one when withMsid is set.
+func (src *source) attributes(withMsid bool) []Attribute {
+    var attrs []Attribute
+    for _, id := range src.ssrcs {
+        has := false
+        for _, a := range src.lines[id] {
+            has = has || strings.HasPrefix(a.Value, id + " msid:")
+            attrs = append(attrs, a)
+        }
+        if withMsid && !has && src.msid != "" {
+            attrs = append(attrs, Attribute{"ssrc", id + " msid:" + src.msid})
+        }
+    }
+    return append(attrs, src.groups...)
+}
+
+// sourceAttributes are the attributes describing the sources of an
+// m-section, rebuilt by the plan conversions.
+var sourceAttributes = []string{"mid", "msid", "ssrc", "ssrc-group"}
+
+// withSources returns a copy of m with the given mid, if any, direction
+// and sources.
+func withSources(m *MediaDescription, mid, direction string, srcs []*source, planB bool) MediaDescription {
+    c := *m.Clone()
+    c.Attributes = nil
+    if mid != "" {
+        c.Attributes = append(c.Attributes, Attribute{"mid", mid})
+    }
+    for _, a := range m.Attributes {
+        if !contains(sourceAttributes, a.Key) && !contains(directions, a.Key) {
+            c.Attributes = append(c.Attributes, a)
+        }
+    }
+    c.Attributes = append(c.Attributes, Attribute{direction, ""})
+    for _, src := range srcs {
+        if !planB && src.msid != "" {
+            c.Attributes = append(c.Attributes, Attribute{"msid", src.msid})
+        }
+        c.Attributes = append(c.Attributes, src.attributes(planB)...)
+    }
+    return c
+}
+
+// splittable reports whether m is a live RTP section whose sources can be
+// split or merged.
+func splittable(m *MediaDescription) bool {
+    return m.Port != 0 && isRTP(m.Proto)
+}
+
+// ToUnifiedPlan converts a Plan B description, with one m-section per media
+// kind carrying many sources, to Unified Plan, with one m-section per
+// source. The first source keeps the Plan B mid and the others get new
+// ones; a Plan B section without a mid has its mids made from its media
+// type, and its entries keep an empty PlanBMid so that PlanBAnswer writes
+// no mid for it. Sections with at most one source, rejected and non-RTP
+// sections are copied. BUNDLE groups list the new mids in place of the Plan B ones.
+func ToUnifiedPlan(sd *SessionDescription) (*SessionDescription, *PlanMapping) {
+    out := sd.Clone()
+    out.MediaDescriptions = nil
+    pm := &PlanMapping{}
+    used := map[string]bool{}
+    for i := range sd.MediaDescriptions {
+        used[sd.MediaDescriptions[i].Mid()] = true
+    }
+    for i := range sd.MediaDescriptions {
+        m := &sd.MediaDescriptions[i]
+        mid := m.Mid()
+        srcs := sources(m)
+        if !splittable(m) || len(srcs) < 2 {
+            msid := ""
+            if len(srcs) == 1 {
+                msid = srcs[0].msid
+            }
+            out.MediaDescriptions = append(out.MediaDescriptions, *m.Clone())
+            pm.Entries = append(pm.Entries, PlanMappingEntry{mid, mid, i, msid})
+            continue
+        }
+        if mid == "" {
+            mid = m.Type
+        }
+        direction := sd.Direction(m)
+        for k, src := range srcs {
+            umid := mid
+            for n := k; k > 0 && used[umid]; n++ {
+                umid = mid + strconv.Itoa(n)
+            }
+            used[umid] = true
+            out.MediaDescriptions = append(out.MediaDescriptions, withSources(m, umid, direction, []*source{src}, false))
+            pm.Entries = append(pm.Entries, PlanMappingEntry{umid, m.Mid(), i, src.msid})
+        }
+    }
+    out.Attributes = rewriteBundle(out.Attributes, pm.UnifiedMids)
+    return out, pm
+}
+
+// ToPlanB converts a Unified Plan description to Plan B by merging the live
+// RTP m-sections of each media kind into one, placed where the first of
+// them was and given the kind as mid. Every source keeps its msid on its
+// a=ssrc lines. The merged section sends when any of its parts sends and
+// receives when any receives; its other attributes come from the first
+// part. Rejected and non-RTP sections are copied.
+func ToPlanB(sd *SessionDescription) (*SessionDescription, *PlanMapping) {
+    pm := &PlanMapping{}
+    kinds := map[string]int{}
+    n := 0
+    for i := range sd.MediaDescriptions {
+        m := &sd.MediaDescriptions[i]
+        e := PlanMappingEntry{m.Mid(), m.Mid(), n, ""}
+        if msid, ok := m.Attribute("msid"); ok {
+            e.MSID = msid
+        }
+        if splittable(m) {
+            e.PlanBMid = m.Type
+            if k, ok := kinds[m.Type]; ok {
+                e.PlanBIndex = k
+            } else {
+                kinds[m.Type] = n
+            }
+        }
+        if e.PlanBIndex == n {
+            n++
+        }
+        pm.Entries = append(pm.Entries, e)
+    }
+    out, _ := pm.merge(sd)
+    return out, pm
+}
+
+// merge rebuilds the Plan B description from the Unified Plan description
+// sd, whose m-sections follow pm.
+func (pm *PlanMapping) merge(sd *SessionDescription) (*SessionDescription, error) {
+    if len(sd.MediaDescriptions) != len(pm.Entries) {
+        return nil, errors.New(badAnswer)
+    }
+    out := sd.Clone()
+    out.MediaDescriptions = nil
+    for i, e := range pm.Entries {
+        if e.PlanBIndex != len(out.MediaDescriptions) {
+            continue
+        }
+        var parts []*MediaDescription
+        for j, f := range pm.Entries {
+            if f.PlanBIndex == e.PlanBIndex && sd.MediaDescriptions[j].Port != 0 {
+                parts = append(parts, &sd.MediaDescriptions[j])
+            }
+        }
+        m := &sd.MediaDescriptions[i]
+        switch {
+        case len(parts) == 0:
+            r := rejectMedia(m)
+            if e.PlanBMid != "" {
+                r.SetAttribute("mid", e.PlanBMid)
+            }
+            out.MediaDescriptions = append(out.MediaDescriptions, r)
+        case e.UnifiedMid == e.PlanBMid && !pm.split(e.PlanBIndex):
+            out.MediaDescriptions = append(out.MediaDescriptions, *parts[0].Clone())
+        default:
+            var send, recv bool
+            var srcs []*source
+            for _, p := range parts {
+                d := sd.Direction(p)
+                send = send || d == "sendrecv" || d == "sendonly"
+                recv = recv || d == "sendrecv" || d == "recvonly"
+                srcs = append(srcs, sources(p)...)
+            }
+            out.MediaDescriptions = append(out.MediaDescriptions, withSources(parts[0], e.PlanBMid, joinDirection(send, recv), srcs, true))
+        }
+    }
+    out.Attributes = rewriteBundle(out.Attributes, func(mid string) []string {
+        if e, ok := pm.PlanBMid(mid); ok {
+            return []string{e}
+        }
+        return nil
+    })
+    return out, nil
+}
+
+// PlanBAnswer converts the Unified Plan answer to an offer returned by
+// ToUnifiedPlan back to Plan B, merging the sections split from each Plan B
+// section.
+func (pm *PlanMapping) PlanBAnswer(answer *SessionDescription) (*SessionDescription, error) {
+    return pm.merge(answer)
+}
+
+// UnifiedAnswer converts the Plan B answer to an offer returned by ToPlanB
+// back to Unified Plan, with one m-section per mapping entry. The sources
+// of each Plan B section are handed out to its Unified Plan sections in
+// order; sections left without one do not send.
+func (pm *PlanMapping) UnifiedAnswer(answer *SessionDescription) (*SessionDescription, error) {
+    out := answer.Clone()
+    out.MediaDescriptions = nil
+    srcs := map[int][]*source{}
+    for _, e := range pm.Entries {
+        if e.PlanBIndex >= len(answer.MediaDescriptions) {
+            return nil, errors.New(badAnswer)
+        }
+        bm := &answer.MediaDescriptions[e.PlanBIndex]
+        if !splittable(bm) || !pm.split(e.PlanBIndex) {
+            c := *bm.Clone()
+            if bm.Port == 0 {
+                c = rejectMedia(bm)
+            }
+            if e.UnifiedMid != "" {
+                c.SetAttribute("mid", e.UnifiedMid)
+            }
+            out.MediaDescriptions = append(out.MediaDescriptions, c)
+            continue
+        }
+        if _, ok := srcs[e.PlanBIndex]; !ok {
+            srcs[e.PlanBIndex] = sources(bm)
+        }
+        var mine []*source
+        if s := srcs[e.PlanBIndex]; len(s) > 0 {
+            mine, srcs[e.PlanBIndex] = s[:1], s[1:]
+        }
+        d := answer.Direction(bm)
+        if len(mine) == 0 {
+            d = joinDirection(false, d == "sendrecv" || d == "recvonly")
+        }
+        out.MediaDescriptions = append(out.MediaDescriptions, withSources(bm, e.UnifiedMid, d, mine, false))
+    }
+    out.Attributes = rewriteBundle(out.Attributes, pm.UnifiedMids)
+    return out, nil
+}
+
+// joinDirection returns the direction attribute for the given send and
+// receive capabilities.
+func joinDirection(send, recv bool) string {
+    switch {
+    case send && recv:
+        return "sendrecv"
+    case send:
+        return "sendonly"
+    case recv:
+        return "recvonly"
+    }
+    return "inactive"
+}
+
+// rewriteBundle returns attrs with the mids of each a=group:BUNDLE replaced
+// by their translation, dropping duplicates.
+func rewriteBundle(attrs []Attribute, translate func(string) []string) []Attribute {
+    out := make([]Attribute, 0, len(attrs))
+    for _, a := range attrs {
+        tokens := strings.Fields(a.Value)
+        if a.Key == "group" && len(tokens) > 0 && tokens[0] == "BUNDLE" {
+            group := []string{tokens[0]}
+            for _, mid := range tokens[1:] {
+                for _, t := range translate(mid) {
+                    if !contains(group[1:], t) {
+                        group = append(group, t)
+                    }
+                }
+            }
+            a.Value = strings.Join(group, " ")
+        }
+        out = append(out, a)
+    }
+    return out
+}
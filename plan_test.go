@@ -0,0 +1,132 @@
+package sdp
+
+import (
+    "strings"
+    "testing"
+    )
+
+var planB = `v=0
+o=- 1 1 IN IP4 192.0.2.1
+s=-
+t=0 0
+a=group:BUNDLE audio video data
+a=msid-semantic: WMS s1 s2
+m=audio 9 UDP/TLS/RTP/SAVPF 111
+c=IN IP4 0.0.0.0
+a=mid:audio
+a=sendrecv
+a=rtpmap:111 opus/48000/2
+a=ssrc:1001 cname:c1
+a=ssrc:1001 msid:s1 a1
+m=video 9 UDP/TLS/RTP/SAVPF 96 97
+c=IN IP4 0.0.0.0
+a=mid:video
+a=sendrecv
+a=rtpmap:96 VP8/90000
+a=rtpmap:97 rtx/90000
+a=ssrc-group:FID 2001 2002
+a=ssrc:2001 cname:c1
+a=ssrc:2001 msid:s1 v1
+a=ssrc:2002 cname:c1
+a=ssrc:2002 msid:s1 v1
+a=ssrc:3001 cname:c2
+a=ssrc:3001 msid:s2 v2
+m=application 9 UDP/DTLS/SCTP webrtc-datachannel
+c=IN IP4 0.0.0.0
+a=mid:data
+a=sctp-port:5000
+`
+
+func TestPlanConversion(t *testing.T) {
+    sd, err := Decode(planB)
+    if err != nil {
+        t.Fatal(err)
+    }
+    unified, pm := ToUnifiedPlan(sd)
+    want := []PlanMappingEntry{
+        {"audio", "audio", 0, "s1 a1"},
+        {"video", "video", 1, "s1 v1"},
+        {"video1", "video", 1, "s2 v2"},
+        {"data", "data", 2, ""},
+    }
+    if len(pm.Entries) != len(want) {
+        t.Fatalf("got entries %v", pm.Entries)
+    }
+    for i, e := range want {
+        if pm.Entries[i] != e {
+            t.Errorf("entry %d: got %v, want %v", i, pm.Entries[i], e)
+        }
+    }
+    if v, _ := unified.Attribute("group"); v != "BUNDLE audio video video1 data" {
+        t.Errorf("unified group: %q", v)
+    }
+    v2 := &unified.MediaDescriptions[2]
+    if msid, _ := v2.Attribute("msid"); msid != "s2 v2" || len(v2.AttributeValues("ssrc")) != 2 || v2.Formats[1] != "97" {
+        t.Errorf("unified video1: %v", v2.Attributes)
+    }
+    if groups := unified.MediaDescriptions[1].AttributeValues("ssrc-group"); len(groups) != 1 || groups[0] != "FID 2001 2002" {
+        t.Errorf("unified video groups: %v", groups)
+    }
+
+    // Merging the Unified Plan description again restores the sources.
+    back, pb := ToPlanB(unified)
+    if len(back.MediaDescriptions) != 3 || back.MediaDescriptions[1].Mid() != "video" {
+        t.Fatalf("plan B: %s", sections(back))
+    }
+    if got, want := back.MediaDescriptions[1].AttributeValues("ssrc"), sd.MediaDescriptions[1].AttributeValues("ssrc"); len(got) != len(want) {
+        t.Errorf("plan B ssrcs: got %v, want %v", got, want)
+    }
+    if v, _ := back.Attribute("group"); v != "BUNDLE audio video data" {
+        t.Errorf("plan B group: %q", v)
+    }
+
+    // A Plan B answer with one video source answers the first Unified Plan
+    // video section; the second one then only receives.
+    answer := back.Clone()
+    answer.MediaDescriptions[1].Attributes = filterAttributes(answer.MediaDescriptions[1].Attributes, func(a Attribute) bool {
+        return a.Key != "ssrc-group" && (a.Key != "ssrc" || a.Value[:4] == "2001")
+    })
+    ua, err := pb.UnifiedAnswer(answer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, want := sections(ua), "audio:audio:9:sendrecv video:video:9:sendrecv video:video1:9:recvonly application:data:9"; got != want {
+        t.Errorf("unified answer: got %q, want %q", got, want)
+    }
+
+    // The reverse: a Unified Plan answer merged for a Plan B offerer.
+    pa, err := pm.PlanBAnswer(unified)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, want := sections(pa), "audio:audio:9:sendrecv video:video:9:sendrecv application:data:9"; got != want {
+        t.Errorf("plan B answer: got %q, want %q", got, want)
+    }
+    if _, err := pm.PlanBAnswer(sd); err == nil {
+        t.Error("expected error for mismatched answer")
+    }
+}
+
+func TestPlanConversionWithoutMid(t *testing.T) {
+    sd, err := Decode(strings.NewReplacer("a=mid:video\n", "", "BUNDLE audio video data", "BUNDLE audio data").Replace(planB))
+    if err != nil {
+        t.Fatal(err)
+    }
+    unified, pm := ToUnifiedPlan(sd)
+    if pm.Entries[1] != (PlanMappingEntry{"video", "", 1, "s1 v1"}) || pm.Entries[2] != (PlanMappingEntry{"video1", "", 1, "s2 v2"}) {
+        t.Errorf("entries: %v", pm.Entries)
+    }
+    if unified.MediaDescriptions[1].Mid() != "video" || unified.MediaDescriptions[2].Mid() != "video1" {
+        t.Errorf("unified mids: %s", sections(unified))
+    }
+    pa, err := pm.PlanBAnswer(unified)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, ok := pa.MediaDescriptions[1].Attribute("mid"); ok {
+        t.Errorf("plan B answer has a mid: %v", pa.MediaDescriptions[1].Attributes)
+    }
+    if got, want := sections(pa), "audio:audio:9:sendrecv video::9:sendrecv application:data:9"; got != want {
+        t.Errorf("plan B answer: got %q, want %q", got, want)
+    }
+}
@@ -0,0 +1,180 @@
+package sdp
+
+import (
+    "errors"
+    "net/netip"
+    "strconv"
+    "strings"
+    )
+
+const (
+    oddRTPPort string = "RTP port is odd"
+    portCountMismatch string = "port count does not match address count"
+    badAddress string = "bad connection address"
+    notRTP string = "not an RTP protocol"
+    badPortRange string = "port range exceeds 65535"
+    tooManyAddresses string = "too many addresses"
+    )
+
+const (
+    // maxAddresses bounds the address count of a multicast c= line, which
+    // comes from untrusted input and is expanded in memory.
+    maxAddresses int = 256
+    )
+
+// PortPair is an RTP port and the RTCP port that goes with it.
+type PortPair struct {
+    RTP  int
+    RTCP int
+}
+
+// PortMapping is one transport flow of a media description: an address and
+// port, plus the RTCP port for RTP protocols as PortPairs reports it.
+type PortMapping struct {
+    Address  string
+    Port     int
+    RTCPPort int
+}
+
+// Ports returns the transport ports of m: Port alone, or NumPorts ports
+// starting at Port. RTP protocols use every other port, the odd ones being
+// taken by RTCP. It fails if the range goes past port 65535.
+func (m *MediaDescription) Ports() ([]int, error) {
+    n, step := m.NumPorts, 1
+    if n < 1 {
+        n = 1
+    }
+    if isRTP(m.Proto) {
+        step = 2
+    }
+    if m.Port < 0 || m.Port + (n-1) * step > 65535 {
+        return nil, errors.New(badPortRange)
+    }
+    ports := make([]int, n)
+    for i := range ports {
+        ports[i] = m.Port + i * step
+    }
+    return ports, nil
+}
+
+// PortPairs returns the RTP/RTCP port pairs of m. With a=rtcp-mux RTCP
+// shares the RTP port, and a=rtcp gives the RTCP port of a single-port media
+// explicitly. Otherwise RFC 3550 requires the RTP port to be even and RTCP
+// to use the next, odd, port.
+func (m *MediaDescription) PortPairs() ([]PortPair, error) {
+    if !isRTP(m.Proto) {
+        return nil, errors.New(notRTP)
+    }
+    ports, err := m.Ports()
+    if err != nil {
+        return nil, err
+    }
+    _, mux := m.Attribute("rtcp-mux")
+    rtcp, explicit := m.Attribute("rtcp")
+    var pairs []PortPair
+    switch {
+    case mux:
+        for _, p := range ports {
+            pairs = append(pairs, PortPair{p, p})
+        }
+    case explicit && len(ports) == 1:
+        tokens := strings.Fields(rtcp)
+        if len(tokens) == 0 {
+            return nil, errors.New(badGrammar)
+        }
+        p, err := parsePort(tokens[0])
+        if err != nil {
+            return nil, err
+        }
+        pairs = append(pairs, PortPair{ports[0], p})
+    case m.Port % 2 != 0:
+        return nil, errors.New(oddRTPPort)
+    default:
+        for _, p := range ports {
+            pairs = append(pairs, PortPair{p, p + 1})
+        }
+    }
+    return pairs, nil
+}
+
+// Addresses expands the address of c. A multicast address may carry a
+// count after the TTL (IP4) or directly (IP6), as in 224.2.1.1/127/3 or
+// ff15::101/3, standing for that many consecutive addresses. Counts above
+// 256 are rejected.
+func (c Connection) Addresses() ([]string, error) {
+    tokens := strings.Split(c.Address, "/")
+    count := 1
+    switch {
+    case c.AddrType == "IP4" && len(tokens) == 3, c.AddrType == "IP6" && len(tokens) == 2:
+        n, err := strconv.Atoi(tokens[len(tokens)-1])
+        if err != nil || n < 1 {
+            return nil, errors.New(badAddress)
+        }
+        if n > maxAddresses {
+            return nil, errors.New(tooManyAddresses)
+        }
+        count = n
+    case len(tokens) > 2:
+        return nil, errors.New(badAddress)
+    }
+    if count == 1 {
+        return []string{tokens[0]}, nil
+    }
+    addr, err := netip.ParseAddr(tokens[0])
+    if err != nil {
+        return nil, err
+    }
+    addrs := []string{}
+    for i := 0; i < count; i++ {
+        if !addr.IsValid() {
+            return nil, errors.New(badAddress)
+        }
+        addrs = append(addrs, addr.String())
+        addr = addr.Next()
+    }
+    return addrs, nil
+}
+
+// PortMappings returns the flows of m with the addresses they use. With a
+// single address every port is on it; otherwise the addresses of the media
+// level c= lines map one-to-one onto the ports, as RFC 4566 section 5.14
+// describes, and their counts must agree.
+func (sd *SessionDescription) PortMappings(m *MediaDescription) ([]PortMapping, error) {
+    conns := m.Connections
+    if len(conns) == 0 {
+        conns = []Connection{sd.Connection}
+    }
+    var addrs []string
+    for _, c := range conns {
+        a, err := c.Addresses()
+        if err != nil {
+            return nil, err
+        }
+        addrs = append(addrs, a...)
+    }
+    ports, err := m.Ports()
+    if err != nil {
+        return nil, err
+    }
+    if len(addrs) > 1 && len(addrs) != len(ports) {
+        return nil, errors.New(portCountMismatch)
+    }
+    var pairs []PortPair
+    if isRTP(m.Proto) {
+        if pairs, err = m.PortPairs(); err != nil {
+            return nil, err
+        }
+    }
+    var mappings []PortMapping
+    for i, p := range ports {
+        pm := PortMapping{Address: addrs[0], Port: p}
+        if len(addrs) > 1 {
+            pm.Address = addrs[i]
+        }
+        if pairs != nil {
+            pm.RTCPPort = pairs[i].RTCP
+        }
+        mappings = append(mappings, pm)
+    }
+    return mappings, nil
+}
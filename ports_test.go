@@ -0,0 +1,84 @@
+package sdp
+
+import (
+    "reflect"
+    "testing"
+    )
+
+func TestMediaPorts(t *testing.T) {
+    sd, err := Decode(s2 + "c=IN IP4 224.2.1.1/127/2\r\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+    m := &sd.MediaDescriptions[0]
+    if m.Port != 49170 || m.NumPorts != 2 {
+        t.Fatalf("wrong ports: %d/%d", m.Port, m.NumPorts)
+    }
+    pairs, err := m.PortPairs()
+    if err != nil || !reflect.DeepEqual(pairs, []PortPair{PortPair{49170, 49171}, PortPair{49172, 49173}}) {
+        t.Errorf("wrong pairs: %v %v", pairs, err)
+    }
+    mappings, err := sd.PortMappings(m)
+    want := []PortMapping{PortMapping{"224.2.1.1", 49170, 49171}, PortMapping{"224.2.1.2", 49172, 49173}}
+    if err != nil || !reflect.DeepEqual(mappings, want) {
+        t.Errorf("wrong mappings: %v %v", mappings, err)
+    }
+    m.Connections = append(m.Connections, Connection{"IN", "IP4", "224.2.1.9/127"})
+    if _, err := sd.PortMappings(m); err == nil {
+        t.Errorf("three addresses for two ports should fail")
+    }
+    if errs := sd.Validate(); len(errs) != 2 {
+        t.Errorf("wrong validation errors: %v", errs)
+    }
+    m.Connections = nil
+    if mappings, err := sd.PortMappings(m); err != nil || mappings[1] != (PortMapping{"131.134.44.12", 49172, 49173}) {
+        t.Errorf("wrong mappings on session address: %v %v", mappings, err)
+    }
+    m.Port = 9
+    if _, err := m.PortPairs(); err == nil {
+        t.Errorf("odd RTP port should fail")
+    }
+    m.NumPorts = 0
+    m.Attributes = []Attribute{Attribute{"rtcp", "53020 IN IP4 126.16.64.4"}}
+    if pairs, err := m.PortPairs(); err != nil || pairs[0] != (PortPair{9, 53020}) {
+        t.Errorf("wrong a=rtcp pair: %v %v", pairs, err)
+    }
+    m.Attributes = []Attribute{Attribute{"rtcp-mux", ""}}
+    if pairs, err := m.PortPairs(); err != nil || pairs[0] != (PortPair{9, 9}) {
+        t.Errorf("wrong rtcp-mux pair: %v %v", pairs, err)
+    }
+    m.Port, m.NumPorts = 65534, 2
+    if _, err := m.Ports(); err == nil {
+        t.Errorf("ports past 65535 should fail")
+    }
+    sd.MediaDescriptions[0] = *m
+    if errs := sd.Validate(); len(errs) != 2 || errs[1].Error() != "sdp: m[0]: port range exceeds 65535" {
+        t.Errorf("wrong validation errors for ports past 65535: %v", errs)
+    }
+    for _, line := range []string{"video 49170/0 RTP/AVP 31", "video 70000 RTP/AVP 31", "video -2 RTP/AVP 31", "video 1/2/3 RTP/AVP 31"} {
+        if err := new(MediaDescription).UnmarshalText([]byte(line)); err == nil {
+            t.Errorf("%q should fail", line)
+        }
+    }
+}
+
+func TestConnectionAddresses(t *testing.T) {
+    for _, c := range []struct {
+        conn Connection
+        want []string
+    }{
+        {Connection{"IN", "IP4", "10.0.0.1"}, []string{"10.0.0.1"}},
+        {Connection{"IN", "IP4", "224.2.1.1/127"}, []string{"224.2.1.1"}},
+        {Connection{"IN", "IP4", "224.2.1.255/127/2"}, []string{"224.2.1.255", "224.2.2.0"}},
+        {Connection{"IN", "IP6", "ff15::101/3"}, []string{"ff15::101", "ff15::102", "ff15::103"}},
+        {Connection{"IN", "IP4", "a/1/2/3"}, nil},
+        {Connection{"IN", "IP4", "224.2.1.1/127/0"}, nil},
+        {Connection{"IN", "IP4", "224.2.1.1/127/1000000000"}, nil},
+        {Connection{"IN", "IP6", "ff15::101/257"}, nil},
+    } {
+        got, err := c.conn.Addresses()
+        if !reflect.DeepEqual(got, c.want) || (err == nil) != (c.want != nil) {
+            t.Errorf("%v: got %v, %v", c.conn, got, err)
+        }
+    }
+}
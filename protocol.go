@@ -0,0 +1,181 @@
+package sdp
+
+import (
+    "strings"
+    "sync"
+    )
+
+// FormatKind says how the format tokens of an m= line are interpreted.
+type FormatKind int
+
+const (
+    // TokenFormats are opaque tokens, such as webrtc-datachannel or t38.
+    TokenFormats FormatKind = iota
+    // PayloadTypeFormats are RTP payload type numbers.
+    PayloadTypeFormats
+    // SCTPPortFormats are SCTP port numbers (legacy DTLS/SCTP).
+    SCTPPortFormats
+    )
+
+// Protocol describes a transport protocol of the m= line.
+type Protocol struct {
+    Name string
+    // RTP is set for protocols carrying RTP and RTCP.
+    RTP bool
+    // SCTP is set for SCTP data channel protocols (RFC 8841).
+    SCTP bool
+    // MSRP is set for MSRP protocols (RFC 4975).
+    MSRP bool
+    // Secure is set for protocols that encrypt media (SRTP, DTLS, TLS).
+    Secure bool
+    // Feedback is set for the RTP/AVPF family (RFC 4585).
+    Feedback bool
+    // Transport is the layering below the protocol, e.g. "UDP", "TCP",
+    // "DTLS/UDP" or "TLS/TCP".
+    Transport string
+    // Formats tells how m= formats are interpreted.
+    Formats FormatKind
+    // Known is false for protocols missing from the registry.
+    Known bool
+}
+
+var (
+    protocolsMu sync.RWMutex
+    protocols = protocolMap(builtinProtocols)
+    )
+
+var builtinProtocols = []Protocol{
+    {Name: "RTP/AVP", RTP: true, Transport: "UDP", Formats: PayloadTypeFormats},
+    {Name: "RTP/AVPF", RTP: true, Feedback: true, Transport: "UDP", Formats: PayloadTypeFormats},
+    {Name: "RTP/SAVP", RTP: true, Secure: true, Transport: "UDP", Formats: PayloadTypeFormats},
+    {Name: "RTP/SAVPF", RTP: true, Secure: true, Feedback: true, Transport: "UDP", Formats: PayloadTypeFormats},
+    {Name: "UDP/TLS/RTP/SAVP", RTP: true, Secure: true, Transport: "DTLS/UDP", Formats: PayloadTypeFormats},
+    {Name: "UDP/TLS/RTP/SAVPF", RTP: true, Secure: true, Feedback: true, Transport: "DTLS/UDP", Formats: PayloadTypeFormats},
+    {Name: "TCP/RTP/AVP", RTP: true, Transport: "TCP", Formats: PayloadTypeFormats},
+    {Name: "TCP/RTP/AVPF", RTP: true, Feedback: true, Transport: "TCP", Formats: PayloadTypeFormats},
+    {Name: "TCP/RTP/SAVP", RTP: true, Secure: true, Transport: "TCP", Formats: PayloadTypeFormats},
+    {Name: "TCP/RTP/SAVPF", RTP: true, Secure: true, Feedback: true, Transport: "TCP", Formats: PayloadTypeFormats},
+    {Name: "TCP/TLS/RTP/SAVP", RTP: true, Secure: true, Transport: "TLS/TCP", Formats: PayloadTypeFormats},
+    {Name: "TCP/TLS/RTP/SAVPF", RTP: true, Secure: true, Feedback: true, Transport: "TLS/TCP", Formats: PayloadTypeFormats},
+    {Name: "TCP/DTLS/RTP/SAVP", RTP: true, Secure: true, Transport: "DTLS/TCP", Formats: PayloadTypeFormats},
+    {Name: "TCP/DTLS/RTP/SAVPF", RTP: true, Secure: true, Feedback: true, Transport: "DTLS/TCP", Formats: PayloadTypeFormats},
+    {Name: "RTP/AVP/TCP", RTP: true, Transport: "TCP", Formats: PayloadTypeFormats},
+    {Name: "UDP/DTLS/SCTP", SCTP: true, Secure: true, Transport: "DTLS/UDP", Formats: TokenFormats},
+    {Name: "TCP/DTLS/SCTP", SCTP: true, Secure: true, Transport: "DTLS/TCP", Formats: TokenFormats},
+    {Name: "DTLS/SCTP", SCTP: true, Secure: true, Transport: "DTLS/UDP", Formats: SCTPPortFormats},
+    {Name: "udp", Transport: "UDP", Formats: TokenFormats},
+    {Name: "udptl", Transport: "UDP", Formats: TokenFormats},
+    {Name: "TCP", Transport: "TCP", Formats: TokenFormats},
+    {Name: "TCP/TLS", Secure: true, Transport: "TLS/TCP", Formats: TokenFormats},
+    {Name: "TCP/MSRP", MSRP: true, Transport: "TCP", Formats: TokenFormats},
+    {Name: "TCP/TLS/MSRP", MSRP: true, Secure: true, Transport: "TLS/TCP", Formats: TokenFormats},
+}
+
+func protocolMap(ps []Protocol) map[string]Protocol {
+    m := map[string]Protocol{}
+    for _, p := range ps {
+        p.Known = true
+        m[p.Name] = p
+    }
+    return m
+}
+
+func protocolNames(ps []Protocol) []string {
+    var names []string
+    for _, p := range ps {
+        names = append(names, p.Name)
+    }
+    return names
+}
+
+// RegisterProtocol adds p to the protocol registry, replacing the
+// properties of any protocol of the same name, and appends its name to
+// TransportTypes. Known is set on registration.
+func RegisterProtocol(p Protocol) {
+    p.Known = true
+    protocolsMu.Lock()
+    defer protocolsMu.Unlock()
+    protocols[p.Name] = p
+    if !contains(TransportTypes, p.Name) {
+        TransportTypes = append(TransportTypes, p.Name)
+    }
+}
+
+// LookupProtocol returns the protocol of TransportTypes called name. Names
+// are matched exactly first, then case-insensitively in TransportTypes
+// order. A name added to TransportTypes without RegisterProtocol is known,
+// with only its RTP property guessed from the name.
+func LookupProtocol(name string) (Protocol, bool) {
+    protocolsMu.RLock()
+    defer protocolsMu.RUnlock()
+    n, ok := lookupName(TransportTypes, name)
+    if !ok {
+        return Protocol{}, false
+    }
+    if p, ok := protocols[n]; ok {
+        return p, true
+    }
+    p := guessProtocol(n)
+    p.Known = true
+    return p, true
+}
+
+// RegisterMediaType appends the media type name to MediaTypes.
+func RegisterMediaType(name string) {
+    protocolsMu.Lock()
+    defer protocolsMu.Unlock()
+    if !contains(MediaTypes, name) {
+        MediaTypes = append(MediaTypes, name)
+    }
+}
+
+// LookupMediaType returns the media type of MediaTypes matching name,
+// exactly first, then case-insensitively in MediaTypes order.
+func LookupMediaType(name string) (string, bool) {
+    protocolsMu.RLock()
+    defer protocolsMu.RUnlock()
+    return lookupName(MediaTypes, name)
+}
+
+func lookupName(names []string, name string) (string, bool) {
+    if contains(names, name) {
+        return name, true
+    }
+    for _, n := range names {
+        if strings.EqualFold(n, name) {
+            return n, true
+        }
+    }
+    return "", false
+}
+
+// Protocol describes the transport protocol of m. Protocols missing from the
+// registry are returned with Known unset; their RTP property is guessed
+// from the name.
+func (m *MediaDescription) Protocol() Protocol {
+    if p, ok := LookupProtocol(m.Proto); ok {
+        return p
+    }
+    return guessProtocol(m.Proto)
+}
+
+// guessProtocol describes an unregistered protocol from its name.
+func guessProtocol(name string) Protocol {
+    p := Protocol{Name: name, RTP: strings.Contains("/" + name + "/", "/RTP/")}
+    if p.RTP {
+        p.Formats = PayloadTypeFormats
+    }
+    return p
+}
+
+// KnownType reports whether the media type of m is registered.
+func (m *MediaDescription) KnownType() bool {
+    _, ok := LookupMediaType(m.Type)
+    return ok
+}
+
+// isRTP reports whether proto carries RTP.
+func isRTP(proto string) bool {
+    m := MediaDescription{Proto: proto}
+    return m.Protocol().RTP
+}
@@ -0,0 +1,87 @@
+package sdp
+
+import (
+    "testing"
+    )
+
+func TestProtocol(t *testing.T) {
+    s := `v=0
+o=- 1 1 IN IP4 192.0.2.1
+s=-
+c=IN IP4 192.0.2.1
+t=0 0
+m=audio 9 UDP/TLS/RTP/SAVPF 111
+m=application 9 UDP/DTLS/SCTP webrtc-datachannel
+m=image 6000 udptl t38
+m=message 7394 TCP/MSRP *
+m=video 9 X-FOO/RTP/AVP 96
+m=x-holo 9 X-BAR 1
+`
+    sd, err := Decode(s)
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []Protocol{
+        {"UDP/TLS/RTP/SAVPF", true, false, false, true, true, "DTLS/UDP", PayloadTypeFormats, true},
+        {"UDP/DTLS/SCTP", false, true, false, true, false, "DTLS/UDP", TokenFormats, true},
+        {"udptl", false, false, false, false, false, "UDP", TokenFormats, true},
+        {"TCP/MSRP", false, false, true, false, false, "TCP", TokenFormats, true},
+        {"X-FOO/RTP/AVP", true, false, false, false, false, "", PayloadTypeFormats, false},
+        {"X-BAR", false, false, false, false, false, "", TokenFormats, false},
+    }
+    for i, want := range tests {
+        if got := sd.MediaDescriptions[i].Protocol(); got != want {
+            t.Errorf("m[%d]: got %+v, want %+v", i, got, want)
+        }
+    }
+    errs := sd.Validate()
+    if len(errs) != 3 {
+        t.Fatalf("expected 3 errors, got %v", errs)
+    }
+    for i, want := range []string{
+        "sdp: m[4]: unknown protocol X-FOO/RTP/AVP",
+        "sdp: m[5]: unknown media type x-holo",
+        "sdp: m[5]: unknown protocol X-BAR",
+    } {
+        if errs[i].Error() != want {
+            t.Errorf("got %q, want %q", errs[i], want)
+        }
+    }
+    transportTypes, mediaTypes := TransportTypes, MediaTypes
+    defer func() {
+        protocolsMu.Lock()
+        delete(protocols, "X-BAR")
+        TransportTypes, MediaTypes = transportTypes, mediaTypes
+        protocolsMu.Unlock()
+    }()
+    RegisterProtocol(Protocol{Name: "X-BAR", Transport: "UDP"})
+    if p := sd.MediaDescriptions[5].Protocol(); !p.Known || p.Transport != "UDP" || !contains(TransportTypes, "X-BAR") {
+        t.Errorf("registered protocol not found: %+v", p)
+    }
+    if p, ok := LookupProtocol("rtp/avp"); !ok || p.Name != "RTP/AVP" {
+        t.Errorf("case-insensitive lookup failed: %+v", p)
+    }
+    TransportTypes = append(TransportTypes, "X-FOO/RTP/AVP")
+    if p := sd.MediaDescriptions[4].Protocol(); !p.Known || !p.RTP {
+        t.Errorf("protocol added to TransportTypes: %+v", p)
+    }
+    RegisterProtocol(Protocol{Name: "x-bar", Transport: "TCP"})
+    for i := 0; i < 10; i++ {
+        if p, _ := LookupProtocol("X-Bar"); p.Transport != "UDP" {
+            t.Fatalf("case-insensitive lookup is not in registration order: %+v", p)
+        }
+    }
+    delete(protocols, "x-bar")
+
+    RegisterMediaType("x-holo")
+    if !sd.MediaDescriptions[5].KnownType() || !contains(MediaTypes, "x-holo") {
+        t.Error("registered media type not known")
+    }
+    if name, ok := LookupMediaType("Image"); !ok || name != "image" {
+        t.Errorf("case-insensitive media type lookup failed: %q", name)
+    }
+    MediaTypes = append(MediaTypes, "model")
+    if _, ok := LookupMediaType("model"); !ok {
+        t.Error("media type added to MediaTypes not known")
+    }
+}
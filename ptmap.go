@@ -0,0 +1,212 @@
+package sdp
+
+import (
+    "errors"
+    "slices"
+    "strconv"
+    "strings"
+    )
+
+// PayloadTypeMap maps the payload types of one m-section to those of
+// another describing the same codecs, as an RTP relay between two call legs
+// needs to rewrite packets.
+type PayloadTypeMap map[int]int
+
+// NewPayloadTypeMap matches the formats of from with those of to by
+// CodecsCompatible, as Answer does. RTX and RED payloads match only when the
+// payloads they reference match too. Formats without a counterpart are left
+// out.
+func NewPayloadTypeMap(from, to *MediaDescription) (PayloadTypeMap, error) {
+    fc, err := from.codecs()
+    if err != nil {
+        return nil, err
+    }
+    tc, err := to.codecs()
+    if err != nil {
+        return nil, err
+    }
+    pm := PayloadTypeMap{}
+    taken := map[string]bool{}
+    // Dependent payloads go second, once the payloads they reference are
+    // mapped.
+    for _, dependent := range []bool{false, true} {
+        for _, f := range from.Formats {
+            c := fc[f]
+            if c.MimeType == "" || (len(from.dependencies(f, c)) > 0) != dependent {
+                continue
+            }
+            deps := from.dependencies(f, c)
+            for i, dep := range deps {
+                deps[i] = pm.format(dep)
+            }
+            for _, g := range to.Formats {
+                d := tc[g]
+                if taken[g] || d.MimeType == "" || !strings.EqualFold(c.MimeType, d.MimeType) || !CodecsCompatible(c, d) {
+                    continue
+                }
+                if !slices.Equal(deps, to.dependencies(g, d)) {
+                    continue
+                }
+                pm[c.PayloadType] = d.PayloadType
+                taken[g] = true
+                break
+            }
+        }
+    }
+    return pm, nil
+}
+
+// Inverse returns the map from the payload types pm maps to back to the
+// payload types mapped.
+func (pm PayloadTypeMap) Inverse() PayloadTypeMap {
+    inverse := PayloadTypeMap{}
+    for from, to := range pm {
+        inverse[to] = from
+    }
+    return inverse
+}
+
+// format maps format f, leaving it unchanged when pm has no entry for it.
+func (pm PayloadTypeMap) format(f string) string {
+    pt, err := strconv.Atoi(f)
+    if err != nil {
+        return f
+    }
+    if to, ok := pm[pt]; ok {
+        return strconv.Itoa(to)
+    }
+    return f
+}
+
+// fmtp maps the payload types referenced by fmtp parameters: the apt= of
+// RTX and the redundant encodings of audio RED (RFC 2198).
+func (pm PayloadTypeMap) fmtp(fmtp string) string {
+    if !strings.Contains(fmtp, "=") {
+        pts := strings.Split(fmtp, "/")
+        for i, pt := range pts {
+            pts[i] = pm.format(strings.TrimSpace(pt))
+        }
+        return strings.Join(pts, "/")
+    }
+    params := strings.Split(fmtp, ";")
+    for i, kv := range params {
+        if k, v, _ := strings.Cut(kv, "="); strings.TrimSpace(k) == "apt" {
+            params[i] = k + "=" + pm.format(v)
+        }
+    }
+    return strings.Join(params, ";")
+}
+
+// RemapPayloadTypes renumbers the formats of m by pm, along with their
+// rtpmap, fmtp and rtcp-fb attributes and the payload types fmtp parameters
+// reference. The caller ensures pm does not map two formats of m to the same
+// payload type. Formats and Attributes are replaced rather than written in
+// place, so slices shared with another description are left alone.
+func (m *MediaDescription) RemapPayloadTypes(pm PayloadTypeMap) {
+    formats := make([]string, len(m.Formats))
+    for i, f := range m.Formats {
+        formats[i] = pm.format(f)
+    }
+    attrs := make([]Attribute, len(m.Attributes))
+    for i, a := range m.Attributes {
+        attrs[i] = a
+        switch a.Key {
+        case "rtpmap", "rtcp-fb":
+            pt, rest, _ := strings.Cut(a.Value, " ")
+            attrs[i].Value = pm.format(pt) + " " + rest
+        case "fmtp":
+            pt, rest, _ := strings.Cut(a.Value, " ")
+            attrs[i].Value = pm.format(pt) + " " + pm.fmtp(rest)
+        }
+    }
+    m.Formats, m.Attributes = formats, attrs
+}
+
+// dynamicPayloadType reports whether pt is in the dynamic range 96-127 or in
+// 35-63, the unassigned range browsers use once 96-127 is full.
+func dynamicPayloadType(pt int) bool {
+    return (pt >= 96 && pt <= 127) || (pt >= 35 && pt <= 63)
+}
+
+// freePayloadTypes are the payload types RenumberPayloadTypes moves
+// formats to, in order of preference. 64-95 are left out as they clash with
+// RTCP packet types when RTP and RTCP are multiplexed (RFC 5761).
+var freePayloadTypes = func() []int {
+    var pts []int
+    for pt := 96; pt <= 127; pt++ {
+        pts = append(pts, pt)
+    }
+    for pt := 35; pt <= 63; pt++ {
+        pts = append(pts, pt)
+    }
+    return pts
+}()
+
+// RenumberPayloadTypes renumbers the dynamic payload types of m, 96-127 and
+// 35-63, to those ref uses for the same codecs. Dynamic payload types
+// without a match in ref keep their number when ref leaves it free and move
+// to one neither section uses otherwise. It returns the map from the old payload types of m to the new
+// ones.
+func (m *MediaDescription) RenumberPayloadTypes(ref *MediaDescription) (PayloadTypeMap, error) {
+    matched, err := NewPayloadTypeMap(m, ref)
+    if err != nil {
+        return nil, err
+    }
+    pts, _ := m.PayloadTypes()
+    pm := PayloadTypeMap{}
+    used := map[int]bool{}
+    for _, f := range ref.Formats {
+        if pt, err := strconv.Atoi(f); err == nil {
+            used[pt] = true
+        }
+    }
+    for _, pt := range pts {
+        if !dynamicPayloadType(pt) {
+            pm[pt] = pt
+        } else if to, ok := matched[pt]; ok && dynamicPayloadType(to) {
+            pm[pt] = to
+        }
+    }
+    for _, to := range pm {
+        used[to] = true
+    }
+    for _, pt := range pts {
+        if _, ok := pm[pt]; ok {
+            continue
+        }
+        to := pt
+        for _, free := range freePayloadTypes {
+            if !used[to] {
+                break
+            }
+            to = free
+        }
+        if used[to] {
+            return nil, errors.New(noPayloadType)
+        }
+        pm[pt] = to
+        used[to] = true
+    }
+    m.RemapPayloadTypes(pm)
+    return pm, nil
+}
+
+// RenumberPayloadTypes renumbers the dynamic payload types of each RTP
+// m-section of sd to match the section of ref at the same index, when it
+// is of the same media type. The result holds the map used for each
+// section, nil for sections left alone.
+func (sd *SessionDescription) RenumberPayloadTypes(ref *SessionDescription) ([]PayloadTypeMap, error) {
+    pms := make([]PayloadTypeMap, len(sd.MediaDescriptions))
+    for i := range sd.MediaDescriptions {
+        m := &sd.MediaDescriptions[i]
+        if i >= len(ref.MediaDescriptions) || ref.MediaDescriptions[i].Type != m.Type || !isRTP(m.Proto) || !isRTP(ref.MediaDescriptions[i].Proto) {
+            continue
+        }
+        pm, err := m.RenumberPayloadTypes(&ref.MediaDescriptions[i])
+        if err != nil {
+            return nil, err
+        }
+        pms[i] = pm
+    }
+    return pms, nil
+}
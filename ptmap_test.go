@@ -0,0 +1,121 @@
+package sdp
+
+import (
+    "strings"
+    "testing"
+    )
+
+func TestPayloadTypeMap(t *testing.T) {
+    a := MediaDescription{Type: "video", Port: 9, Proto: "RTP/AVPF", Formats: []string{"96", "97", "98", "99"}, Attributes: []Attribute{
+        {"rtpmap", "96 VP8/90000"},
+        {"rtcp-fb", "96 nack"},
+        {"rtpmap", "97 rtx/90000"},
+        {"fmtp", "97 apt=96"},
+        {"rtpmap", "98 H264/90000"},
+        {"fmtp", "98 packetization-mode=1;profile-level-id=42e01f"},
+        {"rtpmap", "99 ulpfec/90000"},
+    }}
+    b := MediaDescription{Type: "video", Port: 9, Proto: "RTP/AVPF", Formats: []string{"100", "101", "96", "102"}, Attributes: []Attribute{
+        {"rtpmap", "100 VP8/90000"},
+        {"rtpmap", "101 rtx/90000"},
+        {"fmtp", "101 apt=100"},
+        {"rtpmap", "96 H264/90000"},
+        {"fmtp", "96 profile-level-id=42e01f;packetization-mode=1"},
+        {"rtpmap", "102 H264/90000"},
+        {"fmtp", "102 profile-level-id=42e01f;packetization-mode=0"},
+    }}
+    pm, err := NewPayloadTypeMap(&a, &b)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(pm) != 3 || pm[96] != 100 || pm[97] != 101 || pm[98] != 96 {
+        t.Errorf("map: %v", pm)
+    }
+    if inverse := pm.Inverse(); inverse[100] != 96 {
+        t.Errorf("inverse: %v", inverse)
+    }
+
+    // Compatible fmtp parameters match, as they do in Answer.
+    b.Attributes[4].Value = "96 profile-level-id=42e034;packetization-mode=1;level-asymmetry-allowed=1"
+    if pm, err = NewPayloadTypeMap(&a, &b); err != nil || pm[98] != 96 {
+        t.Errorf("compatible H264: %v %v", pm, err)
+    }
+    b.Attributes[4].Value = "96 profile-level-id=42e01f;packetization-mode=1"
+
+    // ulpfec has no match in b and keeps its number, which b leaves free.
+    pm, err = a.RenumberPayloadTypes(&b)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := strings.Join(a.Formats, " "); got != "100 101 96 99" {
+        t.Errorf("formats: %s", got)
+    }
+    want := []Attribute{
+        {"rtpmap", "100 VP8/90000"},
+        {"rtcp-fb", "100 nack"},
+        {"rtpmap", "101 rtx/90000"},
+        {"fmtp", "101 apt=100"},
+        {"rtpmap", "96 H264/90000"},
+        {"fmtp", "96 packetization-mode=1;profile-level-id=42e01f"},
+        {"rtpmap", "99 ulpfec/90000"},
+    }
+    for i, w := range want {
+        if a.Attributes[i] != w {
+            t.Errorf("attribute %d: %v, want %v", i, a.Attributes[i], w)
+        }
+    }
+
+    // Audio RED references its redundant encodings.
+    c := MediaDescription{Type: "audio", Port: 9, Proto: "RTP/AVP", Formats: []string{"111", "63", "0", "102"}, Attributes: []Attribute{
+        {"rtpmap", "111 opus/48000/2"},
+        {"rtpmap", "63 red/48000/2"},
+        {"fmtp", "63 111/111"},
+        {"rtpmap", "102 iLBC/8000"},
+    }}
+    d := MediaDescription{Type: "audio", Port: 9, Proto: "RTP/AVP", Formats: []string{"109", "102", "8"}, Attributes: []Attribute{
+        {"rtpmap", "109 opus/48000/2"},
+        {"rtpmap", "102 red/48000/2"},
+        {"fmtp", "102 109/109"},
+    }}
+    pm, err = c.RenumberPayloadTypes(&d)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // RED moves from 63 to the 102 of d, and iLBC off 102 to the first
+    // payload type neither section uses.
+    if got := strings.Join(c.Formats, " "); got != "109 102 0 96" || pm[63] != 102 || pm[102] != 96 {
+        t.Errorf("audio formats: %s %v", got, pm)
+    }
+    if fmtp, _ := c.Fmtp("102"); fmtp != "109/109" {
+        t.Errorf("red fmtp: %s", fmtp)
+    }
+}
+
+func TestRenumberClone(t *testing.T) {
+    a := MediaDescription{Type: "audio", Port: 9, Proto: "RTP/AVP", Formats: []string{"120"}, Attributes: []Attribute{{"rtpmap", "120 opus/48000/2"}}}
+    b := MediaDescription{Type: "audio", Port: 9, Proto: "RTP/AVP", Formats: []string{"111"}, Attributes: []Attribute{{"rtpmap", "111 opus/48000/2"}}}
+    c := a.Clone()
+    if _, err := c.RenumberPayloadTypes(&b); err != nil {
+        t.Fatal(err)
+    }
+    if c.Formats[0] != "111" || c.Attributes[0].Value != "111 opus/48000/2" {
+        t.Errorf("clone: %v %v", c.Formats, c.Attributes)
+    }
+    if a.Formats[0] != "120" || a.Attributes[0].Value != "120 opus/48000/2" {
+        t.Errorf("source changed: %v %v", a.Formats, a.Attributes)
+    }
+    // A shallow copy shares its slices and must be left alone too.
+    d := a
+    d.RenumberPayloadTypes(&b)
+    if a.Formats[0] != "120" || a.Attributes[0].Value != "120 opus/48000/2" {
+        t.Errorf("shallow copy source changed: %v %v", a.Formats, a.Attributes)
+    }
+}
+
+func TestDynamicPayloadType(t *testing.T) {
+    for pt, want := range map[int]bool{0: false, 1: false, 20: false, 34: false, 35: true, 63: true, 64: false, 95: false, 96: true, 127: true, 128: false} {
+        if got := dynamicPayloadType(pt); got != want {
+            t.Errorf("%d: got %v", pt, got)
+        }
+    }
+}
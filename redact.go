@@ -0,0 +1,150 @@
+package sdp
+
+import (
+    "net/netip"
+    "strings"
+    )
+
+// Redacted replaces the values Redact masks.
+const Redacted = "REDACTED"
+
+// RedactPolicy chooses the data Redact masks. Addresses lists the ranges
+// whose addresses are masked in o= and c= lines, a=rtcp, ICE candidates and
+// a=remote-candidates. With any ranges set, a candidate that does not parse
+// is masked whole.
+type RedactPolicy struct {
+    ICEPasswords bool // a=ice-pwd
+    Keys         bool // a=crypto inline keys, a=key-mgmt and k= keys
+    Contacts     bool // e= and p= lines
+    Addresses    []netip.Prefix
+}
+
+// PrivateAddresses are the loopback, link-local, private and shared
+// address ranges, which reveal the internal network of a host.
+var PrivateAddresses = []netip.Prefix{
+    netip.MustParsePrefix("10.0.0.0/8"),
+    netip.MustParsePrefix("100.64.0.0/10"),
+    netip.MustParsePrefix("127.0.0.0/8"),
+    netip.MustParsePrefix("169.254.0.0/16"),
+    netip.MustParsePrefix("172.16.0.0/12"),
+    netip.MustParsePrefix("192.168.0.0/16"),
+    netip.MustParsePrefix("::1/128"),
+    netip.MustParsePrefix("fc00::/7"),
+    netip.MustParsePrefix("fe80::/10"),
+}
+
+// DefaultRedactPolicy masks every category, and private addresses.
+var DefaultRedactPolicy = RedactPolicy{true, true, true, PrivateAddresses}
+
+// Redact returns a copy of sd fit for logging, with the data chosen by
+// policy replaced by Redacted. Masked e= and p= lines keep their count.
+func Redact(sd *SessionDescription, policy RedactPolicy) *SessionDescription {
+    sd = sd.Clone()
+    address := func(a string) string {
+        if policy.private(a) {
+            return Redacted
+        }
+        return a
+    }
+    Walk(sd, Visitor{
+        Session: func(sd *SessionDescription) {
+            sd.Origin.UnicastAddr = address(sd.Origin.UnicastAddr)
+            if policy.Keys && sd.Key.Key != "" {
+                sd.Key.Key = Redacted
+            }
+            if policy.Contacts {
+                for i := range sd.Emails {
+                    sd.Emails[i] = Email{Redacted, ""}
+                }
+                for i := range sd.Phones {
+                    sd.Phones[i] = Phone{Redacted, ""}
+                }
+            }
+        },
+        Media: func(s Scope, m *MediaDescription) Action {
+            if policy.Keys && m.Key.Key != "" {
+                m.Key.Key = Redacted
+            }
+            return Keep
+        },
+        Connection: func(s Scope, c *Connection) Action {
+            if a, suffix, _ := strings.Cut(c.Address, "/"); policy.private(a) {
+                c.Address = strings.TrimSuffix(Redacted + "/" + suffix, "/")
+            }
+            return Keep
+        },
+        Attribute: func(s Scope, a *Attribute) Action {
+            switch a.Key {
+            case "ice-pwd":
+                if policy.ICEPasswords {
+                    a.Value = Redacted
+                }
+            case "crypto":
+                if policy.Keys {
+                    a.Value = redactCrypto(a.Value)
+                }
+            case "key-mgmt":
+                if prtcl, _, ok := strings.Cut(a.Value, " "); policy.Keys && ok {
+                    a.Value = prtcl + " " + Redacted
+                }
+            case "rtcp":
+                if tokens := strings.Fields(a.Value); len(tokens) == 4 {
+                    tokens[3] = address(tokens[3])
+                    a.Value = strings.Join(tokens, " ")
+                }
+            case "candidate":
+                if c, err := ParseICECandidate(a.Value); err == nil {
+                    c.Address, c.RelatedAddress = address(c.Address), address(c.RelatedAddress)
+                    a.Value = c.String()
+                } else if len(policy.Addresses) > 0 {
+                    a.Value = Redacted
+                }
+            case "remote-candidates":
+                // component address port, repeated (RFC 8839)
+                tokens := strings.Fields(a.Value)
+                for i := 1; i < len(tokens); i += 3 {
+                    tokens[i] = address(tokens[i])
+                }
+                a.Value = strings.Join(tokens, " ")
+            }
+            return Keep
+        },
+    })
+    return sd
+}
+
+// private reports whether address is in one of the ranges of p.
+func (p RedactPolicy) private(address string) bool {
+    addr, err := netip.ParseAddr(address)
+    if err != nil {
+        return false
+    }
+    addr = addr.Unmap()
+    for _, prefix := range p.Addresses {
+        if prefix.Contains(addr) {
+            return true
+        }
+    }
+    return false
+}
+
+// redactCrypto masks the key and salt of each inline key parameter of an
+// a=crypto value (RFC 4568), keeping the lifetime and MKI that follow.
+func redactCrypto(v string) string {
+    tokens := strings.Fields(v)
+    if len(tokens) < 3 {
+        return v
+    }
+    params := strings.Split(tokens[2], ";")
+    for i, p := range params {
+        if key, ok := strings.CutPrefix(p, "inline:"); ok {
+            _, rest, found := strings.Cut(key, "|")
+            params[i] = "inline:" + Redacted
+            if found {
+                params[i] += "|" + rest
+            }
+        }
+    }
+    tokens[2] = strings.Join(params, ";")
+    return strings.Join(tokens, " ")
+}
@@ -0,0 +1,81 @@
+package sdp
+
+import (
+    "net/netip"
+    "strings"
+    "testing"
+    )
+
+func TestRedact(t *testing.T) {
+    sd, err := Decode(`v=0
+o=jdoe 2890844526 2890842807 IN IP4 10.47.16.5
+s=SDP Seminar
+e=j.doe@example.com (Jane Doe)
+p=+1 617 555-6011
+c=IN IP4 224.2.17.12/127
+t=0 0
+k=clear:secret
+m=audio 49170 RTP/SAVP 0
+c=IN IP6 fe80::1
+k=base64:c2VjcmV0
+a=ice-ufrag:F7gI
+a=ice-pwd:x9cml/YzichV2+XlhiMu8g
+a=crypto:1 AES_CM_128_HMAC_SHA1_80 inline:PS1uQCVeeCFCanVmcjkpPywjNWhcYD0mXXtxaVBR|2^20|1:32
+a=rtcp:49171 IN IP4 192.168.0.2
+a=candidate:1 1 udp 1694498815 203.0.113.7 61000 typ srflx raddr 192.168.0.2 rport 49170
+a=candidate:2 1 udp 2130706431 10.0.0.5 5000 typ host generation
+a=remote-candidates:1 10.0.0.6 5000 2 203.0.113.8 5001
+`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    r := Redact(sd, DefaultRedactPolicy)
+    if sd.Origin.UnicastAddr != "10.47.16.5" {
+        t.Error("original changed")
+    }
+    if r.Origin.UnicastAddr != Redacted || r.Key.Key != Redacted || r.Emails[0].Address != Redacted || r.Phones[0].Address != Redacted {
+        t.Errorf("session: %+v %+v %v %v", r.Origin, r.Key, r.Emails, r.Phones)
+    }
+    // Multicast is not private.
+    if r.Connection.Address != "224.2.17.12/127" {
+        t.Errorf("connection: %s", r.Connection.Address)
+    }
+    m := r.MediaDescriptions[0]
+    if m.Key != (Key{"base64", Redacted}) || sd.MediaDescriptions[0].Key.Key != "c2VjcmV0" {
+        t.Errorf("media key: %+v", m.Key)
+    }
+    if m.Connections[0].Address != Redacted {
+        t.Errorf("media connection: %s", m.Connections[0].Address)
+    }
+    want := map[string]string{
+        "ice-ufrag": "F7gI",
+        "ice-pwd": Redacted,
+        "crypto": "1 AES_CM_128_HMAC_SHA1_80 inline:" + Redacted + "|2^20|1:32",
+        "rtcp": "49171 IN IP4 " + Redacted,
+        "candidate": "1 1 udp 1694498815 203.0.113.7 61000 typ srflx raddr " + Redacted + " rport 49170",
+    }
+    for k, w := range want {
+        if v, _ := m.Attribute(k); v != w {
+            t.Errorf("%s: %s, want %s", k, v, w)
+        }
+    }
+    // A candidate that does not parse is masked whole.
+    if v := m.AttributeValues("candidate"); len(v) != 2 || v[1] != Redacted {
+        t.Errorf("unparsed candidate: %q", v)
+    }
+    if v, _ := m.Attribute("remote-candidates"); v != "1 " + Redacted + " 5000 2 203.0.113.8 5001" {
+        t.Errorf("remote-candidates: %s", v)
+    }
+    if _, err := r.Encode(); err != nil {
+        t.Error(err)
+    }
+
+    policy := RedactPolicy{Addresses: []netip.Prefix{netip.MustParsePrefix("224.0.0.0/4")}}
+    r = Redact(sd, policy)
+    if r.Connection.Address != Redacted + "/127" || r.Origin.UnicastAddr != "10.47.16.5" {
+        t.Errorf("policy addresses: %s %s", r.Connection.Address, r.Origin.UnicastAddr)
+    }
+    if v, _ := r.MediaDescriptions[0].Attribute("ice-pwd"); strings.Contains(v, Redacted) || r.Emails[0].Address == Redacted || r.MediaDescriptions[0].Key.Key == Redacted {
+        t.Error("redacted outside policy")
+    }
+}
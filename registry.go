@@ -0,0 +1,211 @@
+package sdp
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    "sync"
+    )
+
+const (
+    noPayloadType string = "no free dynamic payload type"
+    )
+
+// CodecInfo describes a codec known to a CodecRegistry. PayloadType is the
+// static payload type of RFC 3551, or -1 for codecs using a dynamic one.
+// Fmtp holds the parameters used when the codec is added to a section.
+type CodecInfo struct {
+    Kind        string
+    Name        string
+    ClockRate   int
+    Channels    int
+    PayloadType int
+    Fmtp        string
+}
+
+// RTPMap returns the rtpmap of c with payload type pt.
+func (c CodecInfo) RTPMap(pt int) RTPMap {
+    return RTPMap{pt, c.Name, c.ClockRate, c.Channels}
+}
+
+// CodecRegistry is a set of codecs, looked up by static payload type or by
+// name. It is safe for concurrent use.
+type CodecRegistry struct {
+    mu     sync.RWMutex
+    codecs []CodecInfo
+}
+
+// DefaultCodecRegistry holds the static payload types of RFC 3551 and the
+// common dynamic codecs. MediaDescription accessors use it to describe
+// static payload types that have no a=rtpmap.
+var DefaultCodecRegistry = NewCodecRegistry(
+    CodecInfo{"audio", "PCMU", 8000, 0, 0, ""},
+    CodecInfo{"audio", "GSM", 8000, 0, 3, ""},
+    CodecInfo{"audio", "G723", 8000, 0, 4, ""},
+    CodecInfo{"audio", "DVI4", 8000, 0, 5, ""},
+    CodecInfo{"audio", "DVI4", 16000, 0, 6, ""},
+    CodecInfo{"audio", "LPC", 8000, 0, 7, ""},
+    CodecInfo{"audio", "PCMA", 8000, 0, 8, ""},
+    CodecInfo{"audio", "G722", 8000, 0, 9, ""},
+    CodecInfo{"audio", "L16", 44100, 2, 10, ""},
+    CodecInfo{"audio", "L16", 44100, 0, 11, ""},
+    CodecInfo{"audio", "QCELP", 8000, 0, 12, ""},
+    CodecInfo{"audio", "CN", 8000, 0, 13, ""},
+    CodecInfo{"audio", "MPA", 90000, 0, 14, ""},
+    CodecInfo{"audio", "G728", 8000, 0, 15, ""},
+    CodecInfo{"audio", "DVI4", 11025, 0, 16, ""},
+    CodecInfo{"audio", "DVI4", 22050, 0, 17, ""},
+    CodecInfo{"audio", "G729", 8000, 0, 18, ""},
+    CodecInfo{"video", "CelB", 90000, 0, 25, ""},
+    CodecInfo{"video", "JPEG", 90000, 0, 26, ""},
+    CodecInfo{"video", "nv", 90000, 0, 28, ""},
+    CodecInfo{"video", "H261", 90000, 0, 31, ""},
+    CodecInfo{"video", "MPV", 90000, 0, 32, ""},
+    CodecInfo{"video", "MP2T", 90000, 0, 33, ""},
+    CodecInfo{"video", "H263", 90000, 0, 34, ""},
+    CodecInfo{"audio", "opus", 48000, 2, -1, "minptime=10;useinbandfec=1"},
+    CodecInfo{"audio", "red", 48000, 2, -1, ""},
+    CodecInfo{"audio", "telephone-event", 48000, 0, -1, "0-16"},
+    CodecInfo{"audio", "telephone-event", 8000, 0, -1, "0-16"},
+    CodecInfo{"video", "VP8", 90000, 0, -1, ""},
+    CodecInfo{"video", "VP9", 90000, 0, -1, "profile-id=0"},
+    CodecInfo{"video", "H264", 90000, 0, -1, "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f"},
+    CodecInfo{"video", "AV1", 90000, 0, -1, ""},
+    CodecInfo{"video", "rtx", 90000, 0, -1, ""},
+    CodecInfo{"video", "red", 90000, 0, -1, ""},
+    CodecInfo{"video", "ulpfec", 90000, 0, -1, ""},
+)
+
+// NewCodecRegistry returns a registry holding codecs.
+func NewCodecRegistry(codecs ...CodecInfo) *CodecRegistry {
+    return &CodecRegistry{codecs: append([]CodecInfo(nil), codecs...)}
+}
+
+// Register adds c to r. A codec registered with the kind, name, clock rate
+// and channels of an existing one replaces it.
+func (r *CodecRegistry) Register(c CodecInfo) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    for i, d := range r.codecs {
+        if d.Kind == c.Kind && sameCodec(d.RTPMap(0), c.RTPMap(0)) {
+            r.codecs[i] = c
+            return
+        }
+    }
+    r.codecs = append(r.codecs, c)
+}
+
+// ByPayloadType returns the codec using static payload type pt.
+func (r *CodecRegistry) ByPayloadType(pt int) (CodecInfo, bool) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    for _, c := range r.codecs {
+        if pt >= 0 && c.PayloadType == pt {
+            return c, true
+        }
+    }
+    return CodecInfo{}, false
+}
+
+// ByName returns the codecs of the given kind called name, ignoring case,
+// in registration order. An empty kind matches every kind.
+func (r *CodecRegistry) ByName(kind, name string) []CodecInfo {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    var codecs []CodecInfo
+    for _, c := range r.codecs {
+        if (kind == "" || c.Kind == kind) && strings.EqualFold(c.Name, name) {
+            codecs = append(codecs, c)
+        }
+    }
+    return codecs
+}
+
+// Codecs returns the codecs of the given kind, or all of them when kind is
+// empty, in registration order.
+func (r *CodecRegistry) Codecs(kind string) []CodecInfo {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    var codecs []CodecInfo
+    for _, c := range r.codecs {
+        if kind == "" || c.Kind == kind {
+            codecs = append(codecs, c)
+        }
+    }
+    return codecs
+}
+
+// bundledWith returns the sections of sd sharing a BUNDLE group with m, m
+// included, or m alone when it is not bundled.
+func (sd *SessionDescription) bundledWith(m *MediaDescription) []*MediaDescription {
+    mid := m.Mid()
+    for _, a := range sd.Attributes {
+        tokens := strings.Fields(a.Value)
+        if a.Key != "group" || len(tokens) == 0 || tokens[0] != "BUNDLE" || mid == "" || !contains(tokens[1:], mid) {
+            continue
+        }
+        var sections []*MediaDescription
+        for i := range sd.MediaDescriptions {
+            if contains(tokens[1:], sd.MediaDescriptions[i].Mid()) {
+                sections = append(sections, &sd.MediaDescriptions[i])
+            }
+        }
+        return sections
+    }
+    return []*MediaDescription{m}
+}
+
+// AllocatePayloadType returns the dynamic payload type, in 96-127, for an
+// encoding r with fmtp parameters fmtp in section m of sd. A payload type
+// already describing the same codec in a section BUNDLEd with m is reused;
+// otherwise the lowest payload type unused across those sections is
+// returned, so bundled sections never give one payload type two meanings.
+func (sd *SessionDescription) AllocatePayloadType(m *MediaDescription, r RTPMap, fmtp string) (int, error) {
+    used := map[int]bool{}
+    for _, b := range sd.bundledWith(m) {
+        for _, f := range b.Formats {
+            pt, err := strconv.Atoi(f)
+            if err != nil {
+                continue
+            }
+            used[pt] = true
+            br, ok := b.RTPMap(f)
+            bfmtp, _ := b.Fmtp(f)
+            if ok && pt >= 96 && sameCodec(br, r) && bfmtp == fmtp {
+                return pt, nil
+            }
+        }
+    }
+    for pt := 96; pt <= 127; pt++ {
+        if !used[pt] {
+            return pt, nil
+        }
+    }
+    return 0, errors.New(noPayloadType)
+}
+
+// AddCodec appends codec c to section m of sd, with its static payload
+// type or one from AllocatePayloadType, and its rtpmap and default fmtp. It
+// returns the payload type used.
+func (sd *SessionDescription) AddCodec(m *MediaDescription, c CodecInfo) (int, error) {
+    pt := c.PayloadType
+    if pt < 0 {
+        var err error
+        if pt, err = sd.AllocatePayloadType(m, c.RTPMap(0), c.Fmtp); err != nil {
+            return 0, err
+        }
+    }
+    f := strconv.Itoa(pt)
+    if !contains(m.Formats, f) {
+        m.Formats = append(m.Formats, f)
+    }
+    attrs := filterAttributes(m.Attributes, func(a Attribute) bool {
+        return (a.Key != "rtpmap" && a.Key != "fmtp") || !strings.HasPrefix(a.Value, f + " ")
+    })
+    attrs = append(attrs, Attribute{"rtpmap", c.RTPMap(pt).String()})
+    if c.Fmtp != "" {
+        attrs = append(attrs, Attribute{"fmtp", f + " " + c.Fmtp})
+    }
+    m.Attributes = attrs
+    return pt, nil
+}
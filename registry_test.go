@@ -0,0 +1,85 @@
+package sdp
+
+import (
+    "os"
+    "strconv"
+    "strings"
+    "testing"
+    )
+
+func TestCodecRegistry(t *testing.T) {
+    if c, ok := DefaultCodecRegistry.ByPayloadType(18); !ok || c.Name != "G729" || c.ClockRate != 8000 {
+        t.Errorf("payload type 18: %+v", c)
+    }
+    if _, ok := DefaultCodecRegistry.ByPayloadType(96); ok {
+        t.Error("dynamic payload type found")
+    }
+    if codecs := DefaultCodecRegistry.ByName("audio", "Telephone-Event"); len(codecs) != 2 || codecs[0].Fmtp != "0-16" {
+        t.Errorf("telephone-event: %+v", codecs)
+    }
+    if codecs := DefaultCodecRegistry.ByName("", "red"); len(codecs) != 2 {
+        t.Errorf("red: %+v", codecs)
+    }
+    r := NewCodecRegistry()
+    r.Register(CodecInfo{"audio", "L16", 48000, 2, -1, ""})
+    r.Register(CodecInfo{"audio", "l16", 48000, 2, -1, "channel-order=DV.LRLsRs"})
+    if codecs := r.Codecs("audio"); len(codecs) != 1 || codecs[0].Fmtp == "" {
+        t.Errorf("registered: %+v", codecs)
+    }
+
+    // Static payload types without a=rtpmap are described by the registry.
+    m := MediaDescription{Type: "audio", Port: 49170, Proto: "RTP/AVP", Formats: []string{"0", "18"}}
+    p, err := m.RTPParameters()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(p.Codecs) != 2 || p.Codecs[1].MimeType != "audio/G729" {
+        t.Errorf("codecs: %+v", p.Codecs)
+    }
+}
+
+func TestAddCodec(t *testing.T) {
+    b, err := os.ReadFile("testdata/webrtc-chrome-offer.sdp")
+    if err != nil {
+        t.Fatal(err)
+    }
+    sd, err := Decode(string(b))
+    if err != nil {
+        t.Fatal(err)
+    }
+    video := &sd.MediaDescriptions[1]
+    vp9 := DefaultCodecRegistry.ByName("video", "VP9")[0]
+    // 96, 97, 102 and 103 are taken in the video section, 110 and 111 in
+    // the audio section BUNDLEd with it.
+    pt, err := sd.AddCodec(video, vp9)
+    if err != nil || pt != 98 {
+        t.Fatalf("VP9: %d %v", pt, err)
+    }
+    if r, ok := video.RTPMap("98"); !ok || r.EncodingName != "VP9" {
+        t.Errorf("rtpmap: %+v", r)
+    }
+    if fmtp, _ := video.Fmtp("98"); fmtp != "profile-id=0" {
+        t.Errorf("fmtp: %s", fmtp)
+    }
+    if pt, err := sd.AllocatePayloadType(video, RTPMap{0, "VP8", 90000, 0}, ""); err != nil || pt != 96 {
+        t.Errorf("VP8: %d %v", pt, err)
+    }
+    if pt, err := sd.AllocatePayloadType(video, RTPMap{0, "opus", 48000, 2}, "minptime=10;useinbandfec=1"); err != nil || pt != 111 {
+        t.Errorf("bundled opus: %d %v", pt, err)
+    }
+    pcma := DefaultCodecRegistry.ByName("audio", "PCMA")[0]
+    if pt, err := sd.AddCodec(&sd.MediaDescriptions[0], pcma); err != nil || pt != 8 {
+        t.Errorf("PCMA: %d %v", pt, err)
+    }
+    if got := strings.Join(sd.MediaDescriptions[0].Formats, " "); got != "111 63 9 0 8 13 110 126" {
+        t.Errorf("audio formats: %s", got)
+    }
+
+    m := MediaDescription{Type: "video", Port: 9, Proto: "RTP/AVPF"}
+    for i := 96; i <= 127; i++ {
+        m.Formats = append(m.Formats, strconv.Itoa(i))
+    }
+    if _, err := (&SessionDescription{}).AddCodec(&m, vp9); err == nil {
+        t.Error("no error with every dynamic payload type taken")
+    }
+}
@@ -0,0 +1,177 @@
+package sdp
+
+import (
+    "net/netip"
+    "strconv"
+    "strings"
+    )
+
+// Endpoint is a transport address: an IP address or host name and a port.
+// Port is 0 for fields carrying no port, such as o= and c= lines.
+type Endpoint struct {
+    Address string
+    Port    int
+}
+
+// RewriteAddresses replaces every address of sd, as a media relay placing
+// itself in the media path does: the o= unicast address, session and media
+// c= lines, m= ports, a=rtcp and ICE candidates. rewrite is called once for
+// each distinct endpoint and its results are applied wherever that endpoint
+// appears; the returned map holds them. Address types follow the new
+// addresses, and a section whose new address differs from that of the
+// session c= line gets a c= line of its own. Multicast TTLs and address
+// counts are kept when the new address is multicast too, less the TTL for
+// IP6, and rejected sections keep port 0.
+func RewriteAddresses(sd *SessionDescription, rewrite func(old Endpoint) Endpoint) map[Endpoint]Endpoint {
+    applied := map[Endpoint]Endpoint{}
+    apply := func(old Endpoint) Endpoint {
+        e, ok := applied[old]
+        if !ok {
+            e = rewrite(old)
+            applied[old] = e
+        }
+        return e
+    }
+    session := sd.Connection
+    // The address of the section being walked, before and after rewriting,
+    // and whether its first c= line is still to come.
+    var address, newAddress string
+    var first bool
+    Walk(sd, Visitor{
+        Session: func(sd *SessionDescription) {
+            if sd.Origin.UnicastAddr != "" {
+                e := apply(Endpoint{sd.Origin.UnicastAddr, 0})
+                sd.Origin.UnicastAddr = e.Address
+                sd.Origin.AddrType = addressType(e.Address, sd.Origin.AddrType)
+            }
+        },
+        Media: func(s Scope, m *MediaDescription) Action {
+            address = connectionAddress(session)
+            if len(m.Connections) > 0 {
+                address = connectionAddress(m.Connections[0])
+            }
+            newAddress = address
+            if m.Port != 0 {
+                e := apply(Endpoint{address, m.Port})
+                m.Port, newAddress = e.Port, e.Address
+            } else if address != "" {
+                newAddress = apply(Endpoint{address, 0}).Address
+            }
+            // The session c= line is rewritten by then, and a section
+            // moving elsewhere gets its own, rewritten as its first.
+            if len(m.Connections) == 0 && newAddress != connectionAddress(sd.Connection) {
+                m.Connections = []Connection{session}
+            }
+            first = true
+            return Keep
+        },
+        Connection: func(s Scope, c *Connection) Action {
+            switch {
+            case s.IsSession():
+                if c.Address != "" {
+                    *c = rewriteConnection(*c, apply(Endpoint{connectionAddress(*c), 0}).Address)
+                }
+            case first:
+                *c = rewriteConnection(*c, newAddress)
+                first = false
+            default:
+                *c = rewriteConnection(*c, apply(Endpoint{connectionAddress(*c), 0}).Address)
+            }
+            return Keep
+        },
+        Attribute: func(s Scope, a *Attribute) Action {
+            if s.IsSession() {
+                return Keep
+            }
+            switch a.Key {
+            case "rtcp":
+                a.Value = rewriteRTCP(a.Value, address, newAddress, apply)
+            case "candidate":
+                c, err := ParseICECandidate(a.Value)
+                if err != nil {
+                    return Keep
+                }
+                e := apply(Endpoint{c.Address, c.Port})
+                c.Address, c.Port = e.Address, e.Port
+                if c.RelatedAddress != "" {
+                    e = apply(Endpoint{c.RelatedAddress, c.RelatedPort})
+                    c.RelatedAddress, c.RelatedPort = e.Address, e.Port
+                }
+                a.Value = c.String()
+            }
+            return Keep
+        },
+    })
+    return applied
+}
+
+// rewriteRTCP rewrites an a=rtcp value, port [nettype addrtype address]
+// (RFC 3605). Without an address RTCP goes to that of the section, and one
+// is added when the new RTCP address differs from the section's new one.
+func rewriteRTCP(v, address, newAddress string, apply func(Endpoint) Endpoint) string {
+    tokens := strings.Fields(v)
+    if len(tokens) == 0 {
+        return v
+    }
+    port, err := parsePort(tokens[0])
+    if err != nil {
+        return v
+    }
+    c := Connection{"IN", addressType(address, "IP4"), address}
+    if len(tokens) == 4 {
+        c = Connection{tokens[1], tokens[2], tokens[3]}
+    }
+    e := apply(Endpoint{c.Address, port})
+    s := strconv.Itoa(e.Port)
+    if len(tokens) == 4 || e.Address != newAddress {
+        c = rewriteConnection(c, e.Address)
+        s += " " + c.NetType + " " + c.AddrType + " " + c.Address
+    }
+    return s
+}
+
+// connectionAddress returns the address of c without a multicast TTL or
+// address count.
+func connectionAddress(c Connection) string {
+    address, _, _ := strings.Cut(c.Address, "/")
+    return address
+}
+
+// rewriteConnection replaces the address of c by address and sets the
+// address type to match. A multicast TTL is kept only when both addresses
+// are IP4 multicast and an address count when the new address is
+// multicast; an IP6 address has no TTL, so an IP6 one replacing an IP4 one
+// keeps just the count.
+func rewriteConnection(c Connection, address string) Connection {
+    if c.NetType == "" {
+        c.NetType = "IN"
+    }
+    old := strings.Split(c.Address, "/")
+    if addr, err := netip.ParseAddr(address); err == nil && addr.IsMulticast() && len(old) > 1 {
+        fromIP4 := addressType(old[0], c.AddrType) == "IP4"
+        switch {
+        case addr.Is4() && fromIP4:
+            address += "/" + strings.Join(old[1:], "/")
+        case addr.Is6() && fromIP4 && len(old) == 3:
+            address += "/" + old[2]
+        case addr.Is6() && !fromIP4:
+            address += "/" + old[1]
+        }
+    }
+    c.AddrType = addressType(address, c.AddrType)
+    c.Address = address
+    return c
+}
+
+// addressType returns IP4 or IP6 for an IP address, and def for host names.
+func addressType(address, def string) string {
+    address, _, _ = strings.Cut(address, "/")
+    addr, err := netip.ParseAddr(address)
+    switch {
+    case err != nil:
+        return def
+    case addr.Is4():
+        return "IP4"
+    }
+    return "IP6"
+}
@@ -0,0 +1,91 @@
+package sdp
+
+import (
+    "testing"
+    )
+
+func TestRewriteAddresses(t *testing.T) {
+    sd, err := Decode(`v=0
+o=- 1 1 IN IP4 192.168.1.10
+s=-
+c=IN IP4 192.168.1.10
+t=0 0
+m=audio 49170 RTP/AVP 0
+a=rtcp:49171
+a=candidate:1 1 udp 2130706431 192.168.1.10 49170 typ host
+a=candidate:2 1 udp 1694498815 203.0.113.7 61000 typ srflx raddr 192.168.1.10 rport 49170
+m=video 0 RTP/AVP 31
+m=video 51372 RTP/AVP 31
+c=IN IP4 192.168.1.11
+a=rtcp:51373 IN IP4 192.168.1.12
+`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    next := 40000
+    applied := RewriteAddresses(sd, func(old Endpoint) Endpoint {
+        if old.Port == 0 {
+            return Endpoint{"2001:db8::1", 0}
+        }
+        next += 2
+        return Endpoint{"2001:db8::1", next}
+    })
+    if sd.Origin.AddrType != "IP6" || sd.Origin.UnicastAddr != "2001:db8::1" {
+        t.Errorf("origin: %+v", sd.Origin)
+    }
+    if sd.Connection != (Connection{"IN", "IP6", "2001:db8::1"}) {
+        t.Errorf("session connection: %+v", sd.Connection)
+    }
+    audio := sd.MediaDescriptions[0]
+    if audio.Port != 40002 || len(audio.Connections) != 0 {
+        t.Errorf("audio: %d %v", audio.Port, audio.Connections)
+    }
+    if v, _ := audio.Attribute("rtcp"); v != "40004" {
+        t.Errorf("rtcp: %s", v)
+    }
+    // The host candidate is the m= line endpoint and maps with it.
+    candidates := audio.AttributeValues("candidate")
+    if candidates[0] != "1 1 udp 2130706431 2001:db8::1 40002 typ host" {
+        t.Errorf("host candidate: %s", candidates[0])
+    }
+    if candidates[1] != "2 1 udp 1694498815 2001:db8::1 40006 typ srflx raddr 2001:db8::1 rport 40002" {
+        t.Errorf("srflx candidate: %s", candidates[1])
+    }
+    if sd.MediaDescriptions[1].Port != 0 {
+        t.Errorf("rejected port: %d", sd.MediaDescriptions[1].Port)
+    }
+    video := sd.MediaDescriptions[2]
+    if video.Port != 40008 || video.Connections[0] != (Connection{"IN", "IP6", "2001:db8::1"}) {
+        t.Errorf("video: %d %v", video.Port, video.Connections)
+    }
+    if v, _ := video.Attribute("rtcp"); v != "40010 IN IP6 2001:db8::1" {
+        t.Errorf("video rtcp: %s", v)
+    }
+    if e := applied[Endpoint{"192.168.1.10", 49170}]; e != (Endpoint{"2001:db8::1", 40002}) {
+        t.Errorf("applied: %v", applied)
+    }
+
+    // A section moved to another address than the session gets its own c=.
+    sd, _ = Decode("v=0\r\no=- 1 1 IN IP4 10.0.0.1\r\ns=-\r\nc=IN IP4 224.2.1.1/127\r\nt=0 0\r\nm=audio 5004 RTP/AVP 0\r\n")
+    RewriteAddresses(sd, func(old Endpoint) Endpoint {
+        if old.Port != 0 {
+            return Endpoint{"198.51.100.1", 6000}
+        }
+        return Endpoint{"224.2.1.2", 0}
+    })
+    if sd.Connection.Address != "224.2.1.2/127" {
+        t.Errorf("multicast: %+v", sd.Connection)
+    }
+    if c := sd.MediaDescriptions[0].Connections; len(c) != 1 || c[0].Address != "198.51.100.1" {
+        t.Errorf("media connection: %v", c)
+    }
+
+    // IP6 multicast addresses keep the address count but have no TTL.
+    sd, _ = Decode("v=0\r\no=- 1 1 IN IP4 10.0.0.1\r\ns=-\r\nc=IN IP4 224.2.1.1/127/3\r\nt=0 0\r\nm=audio 0 RTP/AVP 0\r\n")
+    RewriteAddresses(sd, func(old Endpoint) Endpoint {
+        return Endpoint{"ff0e::1", 0}
+    })
+    if sd.Connection != (Connection{"IN", "IP6", "ff0e::1/3"}) {
+        t.Errorf("IP6 multicast: %+v", sd.Connection)
+    }
+}
@@ -0,0 +1,183 @@
+package sdp
+
+import (
+    "errors"
+    "math"
+    "strconv"
+    "strings"
+    )
+
+const (
+    notSCTP string = "not an SCTP protocol"
+    )
+
+const (
+    // DefaultSCTPPort is the SCTP port assumed when a=sctp-port is absent.
+    DefaultSCTPPort = 5000
+    // DefaultMaxMessageSize is the limit assumed when a=max-message-size is
+    // absent (RFC 8841). A MaxMessageSize of 0 means no limit.
+    DefaultMaxMessageSize = 65536
+    )
+
+// SCTPParams describes an SCTP association carrying data channels, as
+// declared by an RFC 8841 (UDP/DTLS/SCTP) or legacy (DTLS/SCTP with
+// a=sctpmap) media description.
+type SCTPParams struct {
+    Port           int
+    Protocol       string // e.g. webrtc-datachannel
+    Streams        int    // legacy a=sctpmap only, 0 when absent
+    MaxMessageSize int
+}
+
+// isSCTP reports whether proto carries SCTP.
+func isSCTP(proto string) bool {
+    m := MediaDescription{Proto: proto}
+    return m.Protocol().SCTP
+}
+
+// isLegacySCTP reports whether m uses the DTLS/SCTP form, whose format is
+// the SCTP port.
+func (m *MediaDescription) isLegacySCTP() bool {
+    return m.Protocol().Formats == SCTPPortFormats
+}
+
+// SCTP returns the SCTP parameters of m, filling in the defaults for absent
+// attributes.
+func (m *MediaDescription) SCTP() (SCTPParams, error) {
+    if !isSCTP(m.Proto) {
+        return SCTPParams{}, errors.New(notSCTP)
+    }
+    if len(m.Formats) == 0 {
+        return SCTPParams{}, errors.New(badGrammar)
+    }
+    p := SCTPParams{Port: DefaultSCTPPort, MaxMessageSize: DefaultMaxMessageSize}
+    var err error
+    if m.isLegacySCTP() {
+        if p.Port, err = parsePort(m.Formats[0]); err != nil {
+            return SCTPParams{}, err
+        }
+        for _, v := range m.AttributeValues("sctpmap") {
+            tokens := strings.Fields(v)
+            if len(tokens) < 2 || tokens[0] != m.Formats[0] {
+                continue
+            }
+            p.Protocol = tokens[1]
+            if len(tokens) > 2 {
+                if p.Streams, err = strconv.Atoi(tokens[2]); err != nil {
+                    return SCTPParams{}, err
+                }
+            }
+        }
+    } else {
+        p.Protocol = m.Formats[0]
+        if v, ok := m.Attribute("sctp-port"); ok {
+            if p.Port, err = parsePort(v); err != nil {
+                return SCTPParams{}, err
+            }
+        }
+    }
+    if v, ok := m.Attribute("max-message-size"); ok {
+        n, err := strconv.ParseUint(v, 10, 64)
+        if err != nil && !errors.Is(err, strconv.ErrRange) {
+            return SCTPParams{}, err
+        }
+        if n > math.MaxInt {
+            n = math.MaxInt
+        }
+        p.MaxMessageSize = int(n)
+    }
+    return p, nil
+}
+
+// SetSCTP writes p to m in the form of its protocol: the port as format with
+// a=sctpmap for DTLS/SCTP, the data channel protocol as format with
+// a=sctp-port and a=max-message-size otherwise. The legacy form has no
+// max-message-size. Other attributes are left in place.
+func (m *MediaDescription) SetSCTP(p SCTPParams) error {
+    if !isSCTP(m.Proto) {
+        return errors.New(notSCTP)
+    }
+    attrs := filterAttributes(m.Attributes, func(a Attribute) bool {
+        return a.Key != "sctpmap" && a.Key != "sctp-port" && a.Key != "max-message-size"
+    })
+    port := strconv.Itoa(p.Port)
+    if m.isLegacySCTP() {
+        m.Formats = []string{port}
+        sctpmap := port + " " + p.Protocol
+        if p.Streams > 0 {
+            sctpmap += " " + strconv.Itoa(p.Streams)
+        }
+        attrs = append(attrs, Attribute{"sctpmap", sctpmap})
+    } else {
+        m.Formats = []string{p.Protocol}
+        attrs = append(attrs, Attribute{"sctp-port", port}, Attribute{"max-message-size", strconv.Itoa(p.MaxMessageSize)})
+    }
+    m.Attributes = attrs
+    return nil
+}
+
+// ConvertSCTP rewrites m to the legacy DTLS/SCTP form when legacy is set,
+// and to the RFC 8841 UDP/DTLS/SCTP form otherwise. TCP/DTLS/SCTP is left
+// as is when converting to the RFC 8841 form.
+func (m *MediaDescription) ConvertSCTP(legacy bool) error {
+    p, err := m.SCTP()
+    if err != nil {
+        return err
+    }
+    switch {
+    case legacy:
+        m.Proto = "DTLS/SCTP"
+        if p.Protocol == "" {
+            p.Protocol = "webrtc-datachannel"
+        }
+    case m.isLegacySCTP():
+        m.Proto = "UDP/DTLS/SCTP"
+        if p.Protocol == "" {
+            p.Protocol = "webrtc-datachannel"
+        }
+        p.Streams = 0
+    }
+    return m.SetSCTP(p)
+}
+
+// NegotiateMaxMessageSize returns the smaller of two max-message-size
+// values, 0 standing for no limit.
+func NegotiateMaxMessageSize(a, b int) int {
+    if a == 0 || (b != 0 && b < a) {
+        return b
+    }
+    return a
+}
+
+// negotiateSCTP answers the SCTP section om from the local section lm,
+// in the form offered. Both sides must agree on the data channel protocol.
+// The answer to an RFC 8841 offer carries the smaller of the two sides'
+// max-message-size values; the answer to a legacy sctpmap offer carries none.
+func negotiateSCTP(om, lm *MediaDescription) ([]string, []Attribute) {
+    op, err := om.SCTP()
+    if err != nil {
+        return nil, nil
+    }
+    lp, err := lm.SCTP()
+    if err != nil {
+        return nil, nil
+    }
+    if op.Protocol != "" && lp.Protocol != "" && op.Protocol != lp.Protocol {
+        return nil, nil
+    }
+    if lp.Protocol == "" {
+        lp.Protocol = op.Protocol
+    }
+    if lp.Protocol == "" {
+        lp.Protocol = "webrtc-datachannel"
+    }
+    lp.MaxMessageSize = NegotiateMaxMessageSize(op.MaxMessageSize, lp.MaxMessageSize)
+    am := MediaDescription{Proto: om.Proto}
+    if !am.isLegacySCTP() {
+        lp.Streams = 0
+    } else if lp.Streams == 0 {
+        lp.Streams = op.Streams
+    }
+    am.SetSCTP(lp)
+    return am.Formats, am.Attributes
+}
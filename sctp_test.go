@@ -0,0 +1,101 @@
+package sdp
+
+import (
+    "math"
+    "strings"
+    "testing"
+    )
+
+var dataChannels = `v=0
+o=- 1 1 IN IP4 192.0.2.1
+s=-
+c=IN IP4 192.0.2.1
+t=0 0
+m=application 9 UDP/DTLS/SCTP webrtc-datachannel
+a=mid:0
+a=sctp-port:5000
+a=max-message-size:262144
+m=application 9 DTLS/SCTP 5000
+a=mid:1
+a=sctpmap:5000 webrtc-datachannel 1024
+`
+
+func TestSCTP(t *testing.T) {
+    sd, err := Decode(dataChannels)
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []SCTPParams{
+        {5000, "webrtc-datachannel", 0, 262144},
+        {5000, "webrtc-datachannel", 1024, DefaultMaxMessageSize},
+    }
+    for i, want := range tests {
+        got, err := sd.MediaDescriptions[i].SCTP()
+        if err != nil || got != want {
+            t.Errorf("m[%d]: got %+v %v, want %+v", i, got, err, want)
+        }
+    }
+    m := sd.MediaDescriptions[1].Clone()
+    if err := m.ConvertSCTP(false); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := m.String() + " " + attributeString(m.Attributes), "application 9 UDP/DTLS/SCTP webrtc-datachannel mid:1 sctp-port:5000 max-message-size:65536"; got != want {
+        t.Errorf("got %q, want %q", got, want)
+    }
+    if err := m.ConvertSCTP(true); err != nil {
+        t.Fatal(err)
+    }
+    if got, want := m.String() + " " + attributeString(m.Attributes), "application 9 DTLS/SCTP 5000 mid:1 sctpmap:5000 webrtc-datachannel"; got != want {
+        t.Errorf("got %q, want %q", got, want)
+    }
+    m.Proto = "UDP/DTLS/SCTP"
+    m.Formats = []string{"webrtc-datachannel"}
+    m.Attributes = []Attribute{{"max-message-size", "2147483648"}}
+    if p, err := m.SCTP(); err != nil || int64(p.MaxMessageSize) != 2147483648 {
+        t.Errorf("large max-message-size: %+v %v", p, err)
+    }
+    m.Attributes[0].Value = "99999999999999999999999"
+    if p, err := m.SCTP(); err != nil || p.MaxMessageSize != math.MaxInt {
+        t.Errorf("max-message-size past MaxInt: %+v %v", p, err)
+    }
+    audio := MediaDescription{Proto: "RTP/AVP"}
+    if _, err := audio.SCTP(); err == nil {
+        t.Error("expected error for RTP/AVP")
+    }
+}
+
+func TestAnswerSCTP(t *testing.T) {
+    offer, err := Decode(dataChannels)
+    if err != nil {
+        t.Fatal(err)
+    }
+    local := NewSessionDescription()
+    local.Origin = Origin{"-", "2", "1", "IN", "IP4", "198.51.100.1"}
+    local.Connection = Connection{"IN", "IP4", "198.51.100.1"}
+    local.MediaDescriptions = []MediaDescription{{
+        Type: "application", Port: 9, Proto: "UDP/DTLS/SCTP", Formats: []string{"webrtc-datachannel"},
+        Attributes: []Attribute{{"sctp-port", "5001"}, {"max-message-size", "131072"}},
+    }}
+    answer, err := Answer(offer, local)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []string{
+        "application 9 UDP/DTLS/SCTP webrtc-datachannel mid:0 sctp-port:5001 max-message-size:131072",
+        "application 9 DTLS/SCTP 5001 mid:1 sctpmap:5001 webrtc-datachannel 1024",
+    }
+    for i, w := range want {
+        m := answer.MediaDescriptions[i]
+        if got := m.String() + " " + attributeString(m.Attributes); got != w {
+            t.Errorf("m[%d]: got %q, want %q", i, got, w)
+        }
+    }
+}
+
+func attributeString(attrs []Attribute) string {
+    var s []string
+    for _, a := range attrs {
+        s = append(s, a.String())
+    }
+    return strings.Join(s, " ")
+}
@@ -1,6 +1,10 @@
 package sdp
 
 import (
+    "bytes"
+    "encoding"
+    "fmt"
+    "strings"
     "time"
     "testing"
     )
@@ -30,7 +34,7 @@ func TestDecode(t *testing.T) {
         t.Error(err)
     }
     if sd.Version != 0 {
-        t.Errorf("Wrong Version: %s", sd.Version)
+        t.Errorf("Wrong Version: %d", sd.Version)
     }
     if sd.Origin.Username != "jdoe" {
         t.Errorf("Wrong Username: %s", sd.Origin.Username)
@@ -108,13 +112,13 @@ func TestDecode(t *testing.T) {
         t.Errorf("Wrong Repeat Offset: %s", sd.Times[0].Repeats[1].Offsets[1])
     }
     if sd.Times[0].Zones[0].Time.Unix() != 2882844526-ntpUnix {
-        t.Errorf("Wrong Zone Time: %s", sd.Times[0].Zones[0].Time.Unix())
+        t.Errorf("Wrong Zone Time: %d", sd.Times[0].Zones[0].Time.Unix())
     }
     if sd.Times[0].Zones[0].Offset != (time.Hour * -1) {
         t.Errorf("Wrong Zone Offset: %s", sd.Times[0].Zones[0].Offset)
     }
     if sd.Times[0].Zones[1].Time.Unix() != 2898848070-ntpUnix {
-        t.Errorf("Wrong Zone Time: %s", sd.Times[0].Zones[1].Time.Unix())
+        t.Errorf("Wrong Zone Time: %d", sd.Times[0].Zones[1].Time.Unix())
     }
     if sd.Times[0].Zones[1].Offset != 0 {
         t.Errorf("Wrong Zone Offset: %s", sd.Times[0].Zones[1].Offset)
@@ -126,31 +130,31 @@ func TestDecode(t *testing.T) {
         t.Errorf("Wrong Media Description Type: %s", sd.MediaDescriptions[0].Type)
     }
     if sd.MediaDescriptions[0].Port != 49170 {
-        t.Errorf("Wrong Media Description Port: %s", sd.MediaDescriptions[0].Port)
+        t.Errorf("Wrong Media Description Port: %d", sd.MediaDescriptions[0].Port)
     }
     if sd.MediaDescriptions[0].NumPorts != 0 {
-        t.Errorf("Wrong Media Connection NumPorts: %s", sd.MediaDescriptions[0].NumPorts)
+        t.Errorf("Wrong Media Connection NumPorts: %d", sd.MediaDescriptions[0].NumPorts)
     }
     if sd.MediaDescriptions[0].Proto != "RTP/AVP" {
         t.Errorf("Wrong Media Description Protocol: %s", sd.MediaDescriptions[0].Proto)
     }
-    if sd.MediaDescriptions[0].Fmt != "0" {
-        t.Errorf("Wrong Connection Address: %s", sd.MediaDescriptions[1].Fmt)
+    if len(sd.MediaDescriptions[0].Formats) != 1 || sd.MediaDescriptions[0].Formats[0] != "0" {
+        t.Errorf("Wrong Media Description Formats: %v", sd.MediaDescriptions[0].Formats)
     }
     if sd.MediaDescriptions[1].Type != "video" {
         t.Errorf("Wrong Media Description Type: %s", sd.MediaDescriptions[1].Type)
     }
     if sd.MediaDescriptions[1].Port != 51372 {
-        t.Errorf("Wrong Media Description Port: %s", sd.MediaDescriptions[1].Port)
+        t.Errorf("Wrong Media Description Port: %d", sd.MediaDescriptions[1].Port)
     }
     if sd.MediaDescriptions[1].NumPorts != 0 {
-        t.Errorf("Wrong Media Connection NumPorts: %s", sd.MediaDescriptions[1].NumPorts)
+        t.Errorf("Wrong Media Connection NumPorts: %d", sd.MediaDescriptions[1].NumPorts)
     }
     if sd.MediaDescriptions[1].Proto != "RTP/AVP" {
         t.Errorf("Wrong Media Description Protocol: %s", sd.MediaDescriptions[1].Proto)
     }
-    if sd.MediaDescriptions[1].Fmt != "99" {
-        t.Errorf("Wrong Connection Address: %s", sd.MediaDescriptions[1].Fmt)
+    if len(sd.MediaDescriptions[1].Formats) != 1 || sd.MediaDescriptions[1].Formats[0] != "99" {
+        t.Errorf("Wrong Media Description Formats: %v", sd.MediaDescriptions[1].Formats)
     }
     if sd.MediaDescriptions[1].Attributes[0].Key != "rtpmap" {
         t.Errorf("Wrong Media Description Attribute Key: %s", sd.MediaDescriptions[1].Attributes[0].Key)
@@ -160,19 +164,17 @@ func TestDecode(t *testing.T) {
     }
 }
 
-var s2 = 
-`v=0
-o=testy 99201111 123 IN IP4 192.168.1.14
-s=SessionName~
-i=A test session
-u=http://example.com
-e=example@web.com (Testy)
-p=123-123-3321 (Testy)
-c=IN IP4 131.134.44.12
-b=CT:128
-k=base64:lol
-m=video 49170/2 RTP/AVP 31
-`
+var s2 = "v=0\r\n" +
+    "o=testy 99201111 123 IN IP4 192.168.1.14\r\n" +
+    "s=SessionName~\r\n" +
+    "i=A test session\r\n" +
+    "u=http://example.com\r\n" +
+    "e=example@web.com (Testy)\r\n" +
+    "p=123-123-3321 (Testy)\r\n" +
+    "c=IN IP4 131.134.44.12\r\n" +
+    "b=CT:128\r\n" +
+    "k=base64:lol\r\n" +
+    "m=video 49170/2 RTP/AVP 31\r\n"
 
 var sd = SessionDescription{
     Version: 0,
@@ -186,7 +188,7 @@ var sd = SessionDescription{
     Bandwidths: []Bandwidth{Bandwidth{"CT","128"}},
     Key: Key{"base64","lol"},
     Attributes: nil,
-    MediaDescriptions: []MediaDescription{MediaDescription{"video",49170,2,"RTP/AVP","31","",nil,nil,Key{},nil}},
+    MediaDescriptions: []MediaDescription{MediaDescription{"video",49170,2,"RTP/AVP",[]string{"31"},"",nil,nil,Key{},nil}},
 }
 
 func TestEncode(t *testing.T) {
@@ -199,6 +201,187 @@ func TestEncode(t *testing.T) {
     }
 }
 
+func TestEncodeOptions(t *testing.T) {
+    td := SessionDescription{
+        Origin: Origin{"-","1","1","IN","IP4","127.0.0.1"},
+        SessionName: "-",
+        Times: []TimeDescription{TimeDescription{
+            Repeats: []Repeat{Repeat{time.Hour * 24 * 7, time.Hour, []time.Duration{0, time.Hour * 25}}},
+        }},
+        Attributes: []Attribute{Attribute{"tool","x"}, Attribute{"recvonly",""}, Attribute{"cat","a"}},
+    }
+    opts := EncodeOptions{LineEnding: LF, AttributeOrder: SortedOrder}
+    var buf bytes.Buffer
+    if err := NewEncoder(&buf, opts).Encode(&td); err != nil {
+        t.Fatal(err)
+    }
+    want := "v=0\no=- 1 1 IN IP4 127.0.0.1\ns=-\nt=0 0\nr=7d 1h 0 25h\na=cat:a\na=recvonly\na=tool:x\n"
+    if buf.String() != want {
+        t.Errorf("wrong SDP:\n%s", buf.String())
+    }
+    if str, _ := td.EncodeWithOptions(EncodeOptions{RawSeconds: true}); !strings.Contains(str, "\r\nr=604800 3600 0 90000\r\n") {
+        t.Errorf("wrong r= line in seconds:\n%s", str)
+    }
+    if _, err := td.EncodeWithOptions(EncodeOptions{LineEnding: "\r"}); err == nil {
+        t.Errorf("expected error for bad line ending")
+    }
+    rt, err := Decode(buf.String())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !rt.Times[0].Start.IsZero() || rt.Times[0].Repeats[0].Offsets[1] != time.Hour * 25 {
+        t.Errorf("wrong round trip: %v", rt.Times[0])
+    }
+}
+
+func TestDurations(t *testing.T) {
+    good := map[string]time.Duration{
+        "0": 0,
+        "604800": time.Hour * 24 * 7,
+        "7d": time.Hour * 24 * 7,
+        "25h": time.Hour * 25,
+        "90m": time.Minute * 90,
+        "30s": time.Second * 30,
+        "-1h": -time.Hour,
+    }
+    for s, want := range good {
+        if d, err := parseDuration(s); err != nil || d != want {
+            t.Errorf("parseDuration(%q) = %v, %v", s, d, err)
+        }
+    }
+    for _, s := range []string{"", "h", "-", "+1h", "1h30m", "1.5h", "1w", "5sd", "1ms", "99999999999999999d"} {
+        if _, err := parseDuration(s); err == nil {
+            t.Errorf("parseDuration(%q) should fail", s)
+        }
+    }
+    if _, err := Decode("v=0\no=- 1 1 IN IP4 127.0.0.1\ns=-\nt=0 0\nr=-7d 1h 0\n"); err == nil {
+        t.Errorf("negative repeat interval should fail")
+    }
+    td := TimeDescription{Zones: []Zone{Zone{time.Unix(2882844526-ntpUnix, 0), -time.Hour}, Zone{time.Unix(2898848070-ntpUnix, 0), 0}}}
+    sd := SessionDescription{Times: []TimeDescription{td}}
+    str, err := sd.EncodeWithOptions(EncodeOptions{LineEnding: LF})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !strings.Contains(str, "\nz=2882844526 -1h 2898848070 0\n") {
+        t.Errorf("wrong z= line:\n%s", str)
+    }
+    for _, r := range []Repeat{Repeat{Interval: -time.Hour}, Repeat{Interval: time.Hour, Active: time.Millisecond}} {
+        sd.Times[0].Repeats = []Repeat{r}
+        if _, err := sd.Encode(); err == nil {
+            t.Errorf("encoding %v should fail", r)
+        }
+    }
+}
+
+func TestTextMarshalers(t *testing.T) {
+    fields := []struct {
+        v interface {
+            encoding.TextMarshaler
+            encoding.TextUnmarshaler
+        }
+        text string
+    }{
+        {new(Origin), "jdoe 2890844526 2890842807 IN IP4 10.47.16.5"},
+        {new(Email), "j.doe@example.com (Jane Doe)"},
+        {new(Phone), "+1 617 555-6011"},
+        {new(Connection), "IN IP6 ff15::101/3"},
+        {new(Bandwidth), "AS:128"},
+        {new(Key), "uri:https://example.com/key"},
+        {new(Attribute), "fingerprint:sha-256 4A:AD:B9"},
+        {new(Attribute), "recvonly"},
+        {new(MediaDescription), "audio 49170 RTP/AVP 0"},
+    }
+    for _, f := range fields {
+        if err := f.v.UnmarshalText([]byte(f.text)); err != nil {
+            t.Errorf("%T %q: %v", f.v, f.text, err)
+            continue
+        }
+        b, err := f.v.MarshalText()
+        if err != nil || string(b) != f.text {
+            t.Errorf("%T: got %q, %v want %q", f.v, b, err, f.text)
+        }
+        if s := fmt.Sprint(f.v); s != f.text {
+            t.Errorf("%T: String() = %q", f.v, s)
+        }
+    }
+    if err := new(Bandwidth).UnmarshalText([]byte("AS 128")); err == nil {
+        t.Errorf("bandwidth without colon should fail")
+    }
+    if s := (MediaDescription{Type: "audio", Port: 9, Proto: "RTP/AVP"}).String(); s != "audio 9 RTP/AVP" {
+        t.Errorf("media without formats: %q", s)
+    }
+}
+
+func TestSessionFields(t *testing.T) {
+    sd, err := Decode(strings.Replace(s2, "k=base64:lol", "k=uri:http://example.com/k", 1) + "b=AS:64\r\na=fingerprint:sha-256 4A:AD:B9\r\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if sd.Bandwidths[0] != (Bandwidth{"CT","128"}) || sd.Key.Key != "http://example.com/k" {
+        t.Errorf("wrong session fields: %v %v", sd.Bandwidths, sd.Key)
+    }
+    if m := sd.MediaDescriptions[0]; m.Bandwidths[0] != (Bandwidth{"AS","64"}) || m.Attributes[0].Value != "sha-256 4A:AD:B9" {
+        t.Errorf("wrong media fields: %v %v", m.Bandwidths, m.Attributes)
+    }
+}
+
+func TestMediaAfterAttributes(t *testing.T) {
+    sd, err := Decode(s1 + "a=sendonly\nm=audio 49172 RTP/AVP 8\na=ptime:20\n")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(sd.MediaDescriptions) != 3 || sd.MediaDescriptions[2].Attributes[0].Key != "ptime" {
+        t.Errorf("wrong media descriptions: %v", sd.MediaDescriptions)
+    }
+    if _, err := Decode(s1 + "u=http://example.com\n"); err == nil {
+        t.Errorf("expected error for u= inside a media section")
+    }
+}
+
+func TestMediaKeyRoundTrip(t *testing.T) {
+    in := strings.Replace(s2, "49170/2", "49170", 1) + "i=camera\r\nc=IN IP4 10.0.0.1\r\nb=AS:64\r\nk=prompt\r\na=recvonly\r\nm=audio 49172 RTP/AVP 0\r\nk=clear:secret\r\n"
+    sd, err := Decode(in)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if sd.MediaDescriptions[0].Key != (Key{"prompt", ""}) || sd.MediaDescriptions[1].Key != (Key{"clear", "secret"}) {
+        t.Errorf("media keys: %v %v", sd.MediaDescriptions[0].Key, sd.MediaDescriptions[1].Key)
+    }
+    out, err := sd.Encode()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if out != in {
+        t.Errorf("round trip:\n%s\nwant\n%s", out, in)
+    }
+}
+
+func TestParseError(t *testing.T) {
+    _, err := Decode("v=0\no=jdoe 1 IN IP4 10.47.16.5\n")
+    perr, ok := err.(*ParseError)
+    if !ok || perr.Line != 2 || perr.Text != "o=jdoe 1 IN IP4 10.47.16.5" {
+        t.Errorf("wrong error: %v", err)
+    }
+}
+
+func TestValidate(t *testing.T) {
+    sd, err := Decode(s1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if errs := sd.Validate(); errs != nil {
+        t.Errorf("unexpected errors: %v", errs)
+    }
+    sd.Version = 1
+    sd.Times = nil
+    sd.Connection = Connection{}
+    errs := sd.Validate()
+    if len(errs) != 4 {
+        t.Errorf("wrong errors: %v", errs)
+    }
+}
+
 func BenchmarkDecode(b *testing.B) {
     for i := 0; i < b.N; i++ {
         _,_ = Decode(s1)
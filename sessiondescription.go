@@ -5,12 +5,31 @@ import (
     )
 
 var (
-    MediaTypes = []string{"audio", "video", "text", "application", "message"}
-    TransportTypes =  []string{"udp", "RTP/AVP", "RTP/SAVP"}
-    AttrTypes = []string{"cat", "keywds", "tool", "ptime", "maxptime", "rtpmap", "orient", "type", "charset", "framerate", "quality", "fmtp", "recvonly", "sendrecv", "sendonly", "inactive", "sdplang", "lang","ice-pwd","ice-ufrag","candidate"}
+    // MediaTypes are the known media types. Add to it with RegisterMediaType,
+    // or directly before any goroutine decodes or validates.
+    MediaTypes = []string{"audio", "video", "text", "application", "message", "image"}
+    // TransportTypes are the known m= protocols, described by Protocol.
+    // RegisterProtocol adds a protocol with its properties; a name added
+    // directly is known, with only its RTP property guessed from the name.
+    TransportTypes = protocolNames(builtinProtocols)
+    // AttrTypes are the attributes Known reports. It starts as the attributes
+    // of RFC 4566 and of the extensions this package understands: ICE, DTLS,
+    // BUNDLE and RTP/RTCP for WebRTC, SDES, SCTP data channels, MSRP and
+    // T.38. Equal uses that built-in list whatever AttrTypes is changed to.
+    AttrTypes = append([]string(nil), builtinAttributes...)
     KeyTypes = []string{"prompt", "clear", "base64", "uri"}
     )
 
+var builtinAttributes = []string{"cat", "keywds", "tool", "ptime", "maxptime", "rtpmap", "orient", "type", "charset", "framerate", "quality", "fmtp", "recvonly", "sendrecv", "sendonly", "inactive", "sdplang", "lang",
+    "ice-pwd", "ice-ufrag", "ice-lite", "ice-options", "candidate", "remote-candidates", "end-of-candidates",
+    "fingerprint", "setup", "connection", "tls-id", "identity",
+    "group", "mid", "bundle-only", "msid", "msid-semantic", "extmap", "extmap-allow-mixed", "rid", "simulcast",
+    "rtcp", "rtcp-fb", "rtcp-mux", "rtcp-rsize", "ssrc", "ssrc-group",
+    "crypto", "key-mgmt",
+    "sctp-port", "sctpmap", "max-message-size",
+    "path", "accept-types", "accept-wrapped-types", "max-size", "file-selector",
+    "T38FaxVersion", "T38MaxBitRate", "T38FaxFillBitRemoval", "T38FaxTranscodingMMR", "T38FaxTranscodingJBIG", "T38FaxRateManagement", "T38FaxMaxBuffer", "T38FaxMaxDatagram", "T38FaxMaxIFP", "T38FaxUdpEC"}
+
 type SessionDescription struct {
     Version           int
     Origin            Origin
@@ -95,15 +114,140 @@ type Attribute struct {
     Value string
 }
 
+// Known reports whether the attribute is one of AttrTypes. Unknown
+// attributes are kept by the decoder as RFC 4566 requires.
+func (a *Attribute) Known() bool {
+    return contains(AttrTypes, a.Key)
+}
+
+// MediaDescription is a media section. Formats holds the format tokens of
+// the m= line in order; for RTP protocols they are payload types listed in
+// order of preference (see PayloadTypes).
 type MediaDescription struct {
     Type        string
     Port        int
     NumPorts    int
     Proto       string
-    Fmt         string
+    Formats     []string
     Info        string
     Connections []Connection
     Bandwidths  []Bandwidth
     Key         Key
     Attributes  []Attribute
 }
+
+// Attribute returns the value of the first session level attribute named key.
+func (sd *SessionDescription) Attribute(key string) (string, bool) {
+    return findAttribute(sd.Attributes, key)
+}
+
+// Attribute returns the value of the first media level attribute named key.
+func (m *MediaDescription) Attribute(key string) (string, bool) {
+    return findAttribute(m.Attributes, key)
+}
+
+// AttributeValues returns the values of all media level attributes named key.
+func (m *MediaDescription) AttributeValues(key string) []string {
+    var values []string
+    for _, a := range m.Attributes {
+        if a.Key == key {
+            values = append(values, a.Value)
+        }
+    }
+    return values
+}
+
+// Mid returns the a=mid identification tag of m, or "" if it has none.
+func (m *MediaDescription) Mid() string {
+    mid, _ := m.Attribute("mid")
+    return mid
+}
+
+func findAttribute(attrs []Attribute, key string) (string, bool) {
+    for _, a := range attrs {
+        if a.Key == key {
+            return a.Value, true
+        }
+    }
+    return "", false
+}
+
+var directions = []string{"sendrecv", "sendonly", "recvonly", "inactive"}
+
+// Direction returns the direction attribute of m, falling back to the
+// session level one and then to sendrecv.
+func (sd *SessionDescription) Direction(m *MediaDescription) string {
+    for _, attrs := range [][]Attribute{m.Attributes, sd.Attributes} {
+        for _, a := range attrs {
+            if contains(directions, a.Key) {
+                return a.Key
+            }
+        }
+    }
+    return "sendrecv"
+}
+
+// MediaConnection returns the connection data that applies to m: its first
+// c= line, or the session level one.
+func (sd *SessionDescription) MediaConnection(m *MediaDescription) Connection {
+    if len(m.Connections) > 0 {
+        return m.Connections[0]
+    }
+    return sd.Connection
+}
+
+// MediaAttribute returns the value of attribute key for m, falling back to
+// the session level attribute.
+func (sd *SessionDescription) MediaAttribute(m *MediaDescription, key string) (string, bool) {
+    if v, ok := m.Attribute(key); ok {
+        return v, true
+    }
+    return sd.Attribute(key)
+}
+
+// The attribute helpers below modify only their receiver. They build a new
+// Attributes slice rather than writing into the existing one, so shallow
+// copies of the receiver keep their attributes; use Clone to get a copy
+// that is independent in every other field.
+
+// SetAttribute sets the value of the first session level attribute named
+// key, adding the attribute if it is missing.
+func (sd *SessionDescription) SetAttribute(key, value string) {
+    sd.Attributes = setAttribute(sd.Attributes, key, value)
+}
+
+// DeleteAttribute removes every session level attribute named key.
+func (sd *SessionDescription) DeleteAttribute(key string) {
+    sd.Attributes = deleteAttribute(sd.Attributes, key)
+}
+
+// SetAttribute sets the value of the first media level attribute named key,
+// adding the attribute if it is missing.
+func (m *MediaDescription) SetAttribute(key, value string) {
+    m.Attributes = setAttribute(m.Attributes, key, value)
+}
+
+// DeleteAttribute removes every media level attribute named key.
+func (m *MediaDescription) DeleteAttribute(key string) {
+    m.Attributes = deleteAttribute(m.Attributes, key)
+}
+
+func setAttribute(attrs []Attribute, key, value string) []Attribute {
+    c := make([]Attribute, 0, len(attrs)+1)
+    found := false
+    for _, a := range attrs {
+        if a.Key == key && !found {
+            a.Value = value
+            found = true
+        }
+        c = append(c, a)
+    }
+    if !found {
+        c = append(c, Attribute{key, value})
+    }
+    return c
+}
+
+func deleteAttribute(attrs []Attribute, key string) []Attribute {
+    return filterAttributes(attrs, func(a Attribute) bool { return a.Key != key })
+}
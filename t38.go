@@ -0,0 +1,183 @@
+package sdp
+
+import (
+    "errors"
+    "slices"
+    "strconv"
+    "strings"
+    )
+
+const (
+    notT38 string = "not a T.38 media description"
+    )
+
+// T.38 error correction schemes of a=T38FaxUdpEC.
+const (
+    T38UDPRedundancy = "t38UDPRedundancy"
+    T38UDPFEC        = "t38UDPFEC"
+    )
+
+// T38Params are the T.38 fax attributes of an image media description
+// (ITU-T T.38 Annex D). Integer fields are 0 when absent.
+type T38Params struct {
+    Version         int
+    MaxBitRate      int
+    FillBitRemoval  bool
+    TranscodingMMR  bool
+    TranscodingJBIG bool
+    RateManagement  string // localTCF or transferredTCF
+    MaxBuffer       int
+    MaxDatagram     int
+    MaxIFP          int
+    UDPEC           string // t38UDPRedundancy, t38UDPFEC or empty
+}
+
+// isT38 reports whether m carries T.38.
+func (m *MediaDescription) isT38() bool {
+    return slices.Contains(m.Formats, "t38")
+}
+
+// isT38Attribute reports whether key names a T.38 attribute. Endpoints
+// disagree on case, so keys are matched case-insensitively.
+func isT38Attribute(key string) bool {
+    return len(key) > 3 && strings.EqualFold(key[:3], "T38")
+}
+
+// T38 returns the T.38 parameters of m. Attribute names are matched
+// case-insensitively and flags may be given bare or as :0 or :1.
+func (m *MediaDescription) T38() (T38Params, error) {
+    var p T38Params
+    if !m.isT38() {
+        return p, errors.New(notT38)
+    }
+    for _, a := range m.Attributes {
+        if !isT38Attribute(a.Key) {
+            continue
+        }
+        var n *int
+        var flag *bool
+        switch strings.ToLower(a.Key) {
+        case "t38faxversion":
+            n = &p.Version
+        case "t38maxbitrate":
+            n = &p.MaxBitRate
+        case "t38faxmaxbuffer":
+            n = &p.MaxBuffer
+        case "t38faxmaxdatagram":
+            n = &p.MaxDatagram
+        case "t38faxmaxifp":
+            n = &p.MaxIFP
+        case "t38faxfillbitremoval":
+            flag = &p.FillBitRemoval
+        case "t38faxtranscodingmmr":
+            flag = &p.TranscodingMMR
+        case "t38faxtranscodingjbig":
+            flag = &p.TranscodingJBIG
+        case "t38faxratemanagement":
+            p.RateManagement = a.Value
+        case "t38faxudpec":
+            p.UDPEC = a.Value
+        }
+        var err error
+        switch {
+        case n != nil:
+            *n, err = strconv.Atoi(a.Value)
+        case flag != nil:
+            *flag = a.Value != "0"
+            if a.Value != "" && a.Value != "0" && a.Value != "1" {
+                err = errors.New(badGrammar)
+            }
+        }
+        if err != nil {
+            return T38Params{}, err
+        }
+    }
+    return p, nil
+}
+
+// SetT38 replaces the T.38 attributes of m with p. Flags are written bare
+// and unset integer fields other than Version are omitted.
+func (m *MediaDescription) SetT38(p T38Params) {
+    attrs := filterAttributes(m.Attributes, func(a Attribute) bool { return !isT38Attribute(a.Key) })
+    attrs = append(attrs, Attribute{"T38FaxVersion", strconv.Itoa(p.Version)})
+    num := func(key string, v int) {
+        if v > 0 {
+            attrs = append(attrs, Attribute{key, strconv.Itoa(v)})
+        }
+    }
+    flag := func(key string, v bool) {
+        if v {
+            attrs = append(attrs, Attribute{key, ""})
+        }
+    }
+    str := func(key string, v string) {
+        if v != "" {
+            attrs = append(attrs, Attribute{key, v})
+        }
+    }
+    num("T38MaxBitRate", p.MaxBitRate)
+    flag("T38FaxFillBitRemoval", p.FillBitRemoval)
+    flag("T38FaxTranscodingMMR", p.TranscodingMMR)
+    flag("T38FaxTranscodingJBIG", p.TranscodingJBIG)
+    str("T38FaxRateManagement", p.RateManagement)
+    num("T38FaxMaxBuffer", p.MaxBuffer)
+    num("T38FaxMaxDatagram", p.MaxDatagram)
+    num("T38FaxMaxIFP", p.MaxIFP)
+    str("T38FaxUdpEC", p.UDPEC)
+    m.Attributes = attrs
+}
+
+// AnswerT38 returns the T.38 parameters answering offer from an endpoint
+// supporting local: the lower version and bit rate, the options both sides
+// support, the offered rate management and the weaker error correction.
+// Buffer and datagram sizes describe what the answerer can receive and are
+// taken from local.
+func AnswerT38(offer, local T38Params) T38Params {
+    a := T38Params{
+        Version: min(offer.Version, local.Version),
+        MaxBitRate: offer.MaxBitRate,
+        FillBitRemoval: offer.FillBitRemoval && local.FillBitRemoval,
+        TranscodingMMR: offer.TranscodingMMR && local.TranscodingMMR,
+        TranscodingJBIG: offer.TranscodingJBIG && local.TranscodingJBIG,
+        RateManagement: offer.RateManagement,
+        MaxBuffer: local.MaxBuffer,
+        MaxDatagram: local.MaxDatagram,
+        MaxIFP: local.MaxIFP,
+        UDPEC: offer.UDPEC,
+    }
+    if a.MaxBitRate == 0 || (local.MaxBitRate > 0 && local.MaxBitRate < a.MaxBitRate) {
+        a.MaxBitRate = local.MaxBitRate
+    }
+    if t38ECRank(local.UDPEC) < t38ECRank(offer.UDPEC) {
+        a.UDPEC = local.UDPEC
+    }
+    return a
+}
+
+func t38ECRank(ec string) int {
+    switch ec {
+    case T38UDPRedundancy:
+        return 1
+    case T38UDPFEC:
+        return 2
+    }
+    return 0
+}
+
+// negotiateT38 answers the T.38 section om from the local section lm.
+func negotiateT38(om, lm *MediaDescription) ([]string, []Attribute) {
+    if !lm.isT38() {
+        return nil, nil
+    }
+    op, err := om.T38()
+    if err != nil {
+        return nil, nil
+    }
+    lp, err := lm.T38()
+    if err != nil {
+        return nil, nil
+    }
+    var am MediaDescription
+    am.SetT38(AnswerT38(op, lp))
+    return []string{"t38"}, am.Attributes
+}
@@ -0,0 +1,59 @@
+package sdp
+
+import (
+    "testing"
+    )
+
+var faxOffer = `v=0
+o=- 1 1 IN IP4 192.0.2.1
+s=-
+c=IN IP4 192.0.2.1
+t=0 0
+m=image 5000 udptl t38
+a=T38FaxVersion:1
+a=T38MaxBitRate:14400
+a=T38FaxFillBitRemoval:0
+a=t38FaxTranscodingMMR
+a=T38FaxRateManagement:transferredTCF
+a=T38FaxMaxDatagram:400
+a=T38FaxUdpEC:t38UDPFEC
+`
+
+func TestT38(t *testing.T) {
+    sd, err := Decode(faxOffer)
+    if err != nil {
+        t.Fatal(err)
+    }
+    m := &sd.MediaDescriptions[0]
+    p, err := m.T38()
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := T38Params{Version: 1, MaxBitRate: 14400, TranscodingMMR: true, RateManagement: "transferredTCF", MaxDatagram: 400, UDPEC: T38UDPFEC}
+    if p != want {
+        t.Errorf("got %+v, want %+v", p, want)
+    }
+    c := m.Clone()
+    c.SetT38(p)
+    if q, _ := c.T38(); q != p {
+        t.Errorf("round trip: got %+v, want %+v", q, p)
+    }
+    local := NewSessionDescription()
+    local.Origin = Origin{"-", "2", "1", "IN", "IP4", "198.51.100.1"}
+    local.Connection = Connection{"IN", "IP4", "198.51.100.1"}
+    lm := MediaDescription{Type: "image", Port: 6000, Proto: "udptl", Formats: []string{"t38"}}
+    lm.SetT38(T38Params{Version: 0, MaxBitRate: 9600, TranscodingMMR: true, RateManagement: "localTCF", MaxDatagram: 272, UDPEC: T38UDPRedundancy})
+    local.MediaDescriptions = []MediaDescription{lm}
+    answer, err := Answer(sd, local)
+    if err != nil {
+        t.Fatal(err)
+    }
+    am := answer.MediaDescriptions[0]
+    if got, want := am.String() + " " + attributeString(am.Attributes), "image 6000 udptl t38 sendrecv T38FaxVersion:0 T38MaxBitRate:9600 T38FaxTranscodingMMR T38FaxRateManagement:transferredTCF T38FaxMaxDatagram:272 T38FaxUdpEC:t38UDPRedundancy"; got != want {
+        t.Errorf("got %q\nwant %q", got, want)
+    }
+    audio := MediaDescription{Formats: []string{"0"}}
+    if _, err := audio.T38(); err == nil {
+        t.Error("expected error for non-T.38 media")
+    }
+}
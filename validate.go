@@ -0,0 +1,97 @@
+package sdp
+
+import (
+    "strconv"
+    )
+
+var (
+    NetTypes = []string{"IN"}
+    AddrTypes = []string{"IP4", "IP6"}
+    )
+
+// ValidationError describes a field of a decoded or constructed session
+// description that does not satisfy RFC 4566.
+type ValidationError struct {
+    Field string // SDP line type, prefixed by m[i] for media level fields
+    Msg string
+}
+
+func (e *ValidationError) Error() string {
+    return "sdp: " + e.Field + ": " + e.Msg
+}
+
+// Validate checks sd against the rules of RFC 4566 that the decoder does not
+// enforce and returns every violation found, or nil. Media types and
+// protocols the decoder accepted but does not know are reported too.
+func (sd *SessionDescription) Validate() []error {
+    var errs []error
+    invalid := func(field, msg string) {
+        errs = append(errs, &ValidationError{field, msg})
+    }
+    if sd.Version != 0 {
+        invalid("v", "unsupported version " + strconv.Itoa(sd.Version))
+    }
+    o := sd.Origin
+    if o.Username == "" || o.SessionId == "" || o.SessionVersion == "" || o.UnicastAddr == "" {
+        invalid("o", "missing origin field")
+    }
+    if !contains(NetTypes, o.NetType) || !contains(AddrTypes, o.AddrType) {
+        invalid("o", "unknown network or address type")
+    }
+    if sd.SessionName == "" {
+        invalid("s", "empty session name")
+    }
+    if len(sd.Times) == 0 {
+        invalid("t", "missing time description")
+    }
+    for _, t := range sd.Times {
+        if !t.Stop.IsZero() && t.Stop.Before(t.Start) {
+            invalid("t", "stop time before start time")
+        }
+    }
+    if sd.Connection != (Connection{}) {
+        validateConnection(sd.Connection, "c", invalid)
+    }
+    if sd.Key != (Key{}) && !contains(KeyTypes, sd.Key.Method) {
+        invalid("k", "unknown method " + sd.Key.Method)
+    }
+    for i, m := range sd.MediaDescriptions {
+        field := "m[" + strconv.Itoa(i) + "]"
+        if m.Type == "" || m.Proto == "" || len(m.Formats) == 0 {
+            invalid(field, "missing media field")
+        }
+        if m.Type != "" && !m.KnownType() {
+            invalid(field, "unknown media type " + m.Type)
+        }
+        if m.Proto != "" && !m.Protocol().Known {
+            invalid(field, "unknown protocol " + m.Proto)
+        }
+        if m.Port < 0 || m.Port > 65535 {
+            invalid(field, "port out of range")
+        }
+        if len(m.Connections) == 0 && sd.Connection == (Connection{}) {
+            invalid(field + " c", "no connection data at session or media level")
+        }
+        for _, c := range m.Connections {
+            validateConnection(c, field + " c", invalid)
+        }
+        if m.NumPorts > 0 || len(m.Connections) > 1 {
+            if _, err := sd.PortMappings(&m); err != nil {
+                invalid(field, err.Error())
+            }
+        }
+        if m.Key != (Key{}) && !contains(KeyTypes, m.Key.Method) {
+            invalid(field + " k", "unknown method " + m.Key.Method)
+        }
+    }
+    return errs
+}
+
+func validateConnection(c Connection, field string, invalid func(string, string)) {
+    if !contains(NetTypes, c.NetType) || !contains(AddrTypes, c.AddrType) {
+        invalid(field, "unknown network or address type")
+    }
+    if c.Address == "" {
+        invalid(field, "missing address")
+    }
+}
@@ -0,0 +1,102 @@
+package sdp
+
+import (
+    "strconv"
+    )
+
+// Scope locates an element visited by Walk: the session level, or the media
+// section of index Media.
+type Scope struct {
+    Media int // -1 at session level
+}
+
+// SessionScope is the scope of session level elements.
+var SessionScope = Scope{-1}
+
+// IsSession reports whether s is the session level.
+func (s Scope) IsSession() bool {
+    return s.Media < 0
+}
+
+// String returns "session", or m[i] as in ValidationError fields.
+func (s Scope) String() string {
+    if s.IsSession() {
+        return "session"
+    }
+    return "m[" + strconv.Itoa(s.Media) + "]"
+}
+
+// Action tells Walk what to do with an element once visited.
+type Action int
+
+const (
+    Keep Action = iota // keep the element, with any changes made to it
+    Delete             // remove the element
+    Skip               // keep a media section but do not visit its elements
+    )
+
+// Visitor holds the callbacks of Walk, any of which may be nil. Callbacks
+// receive pointers into the session description and may change elements in
+// place; returning Delete removes them. Session is called first and may
+// change any session level field. Media is called for each media section
+// before its connections, bandwidths and attributes are visited, and Scope
+// gives the index of the section in sd as it was before the walk.
+type Visitor struct {
+    Session    func(sd *SessionDescription)
+    Media      func(s Scope, m *MediaDescription) Action
+    Connection func(s Scope, c *Connection) Action
+    Bandwidth  func(s Scope, b *Bandwidth) Action
+    Attribute  func(s Scope, a *Attribute) Action
+}
+
+// Walk visits the session level fields of sd, its c= line, bandwidths and
+// attributes, then each media section followed by its own c= lines,
+// bandwidths and attributes, in order. A deleted session c= line is left
+// empty.
+func Walk(sd *SessionDescription, v Visitor) {
+    if v.Session != nil {
+        v.Session(sd)
+    }
+    if v.Connection != nil && sd.Connection != (Connection{}) && v.Connection(SessionScope, &sd.Connection) == Delete {
+        sd.Connection = Connection{}
+    }
+    walkElements(SessionScope, &sd.Bandwidths, v.Bandwidth)
+    walkElements(SessionScope, &sd.Attributes, v.Attribute)
+    var media []MediaDescription
+    for i := range sd.MediaDescriptions {
+        s, m := Scope{i}, &sd.MediaDescriptions[i]
+        action := Keep
+        if v.Media != nil {
+            action = v.Media(s, m)
+        }
+        if action == Delete {
+            continue
+        }
+        if action != Skip {
+            walkElements(s, &m.Connections, v.Connection)
+            walkElements(s, &m.Bandwidths, v.Bandwidth)
+            walkElements(s, &m.Attributes, v.Attribute)
+        }
+        media = append(media, *m)
+    }
+    if len(media) != len(sd.MediaDescriptions) {
+        sd.MediaDescriptions = media
+    }
+}
+
+// walkElements calls visit on each element of *elems and removes those
+// deleted, leaving the slice untouched when none was.
+func walkElements[T any](s Scope, elems *[]T, visit func(Scope, *T) Action) {
+    if visit == nil {
+        return
+    }
+    var kept []T
+    for i := range *elems {
+        if visit(s, &(*elems)[i]) != Delete {
+            kept = append(kept, (*elems)[i])
+        }
+    }
+    if len(kept) != len(*elems) {
+        *elems = kept
+    }
+}
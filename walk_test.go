@@ -0,0 +1,75 @@
+package sdp
+
+import (
+    "os"
+    "testing"
+    )
+
+func TestWalk(t *testing.T) {
+    b, err := os.ReadFile("testdata/webrtc-chrome-offer.sdp")
+    if err != nil {
+        t.Fatal(err)
+    }
+    sd, err := Decode(string(b))
+    if err != nil {
+        t.Fatal(err)
+    }
+    var scopes []string
+    extmaps := 0
+    Walk(sd, Visitor{
+        Session: func(sd *SessionDescription) {
+            sd.SessionName = "relay"
+        },
+        Media: func(s Scope, m *MediaDescription) Action {
+            scopes = append(scopes, s.String())
+            switch m.Type {
+            case "application":
+                return Delete
+            case "video":
+                return Skip
+            }
+            return Keep
+        },
+        Attribute: func(s Scope, a *Attribute) Action {
+            switch a.Key {
+            case "extmap":
+                extmaps++
+                return Delete
+            case "ice-ufrag":
+                a.Value = s.String()
+            }
+            return Keep
+        },
+    })
+    if sd.SessionName != "relay" {
+        t.Errorf("session name: %s", sd.SessionName)
+    }
+    if len(scopes) != 3 || scopes[2] != "m[2]" || len(sd.MediaDescriptions) != 2 {
+        t.Fatalf("media: %v %d", scopes, len(sd.MediaDescriptions))
+    }
+    audio, video := sd.MediaDescriptions[0], sd.MediaDescriptions[1]
+    if v, _ := audio.Attribute("ice-ufrag"); v != "m[0]" {
+        t.Errorf("audio ice-ufrag: %s", v)
+    }
+    if _, ok := audio.Attribute("extmap"); ok || extmaps != 4 {
+        t.Errorf("extmap left: %d", extmaps)
+    }
+    if v, _ := video.Attribute("ice-ufrag"); v == "m[1]" {
+        t.Error("skipped section visited")
+    }
+
+    // Deleting the session c= line leaves it empty.
+    sd.Connection = Connection{"IN", "IP4", "192.0.2.1"}
+    Walk(sd, Visitor{Connection: func(s Scope, c *Connection) Action {
+        if s.IsSession() {
+            return Delete
+        }
+        return Keep
+    }})
+    if sd.Connection != (Connection{}) {
+        t.Errorf("session connection: %+v", sd.Connection)
+    }
+    if len(sd.MediaDescriptions[0].Connections) != 1 {
+        t.Errorf("media connections: %v", sd.MediaDescriptions[0].Connections)
+    }
+}